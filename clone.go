@@ -3,6 +3,7 @@ package ref
 import (
 	"bytes"
 	"encoding/gob"
+	"fmt"
 	"github.com/hedzr/log"
 	"gopkg.in/hedzr/errors.v2"
 	"reflect"
@@ -16,8 +17,13 @@ type (
 		Copy(fromVar, toVar interface{}, Opts ...Opt) (err error)
 	}
 
-	// Opt is functional option functor for Cloner
-	Opt func(c Cloner)
+	// Opt is functional option functor for Cloner.Copy, it's the same
+	// as CloneOpt, so that the With... helpers can be passed to Copy:
+	//
+	//	ref.DefaultCloner.Copy(a, &b, ref.IgnoreFields("ID"), ref.KeepZero())
+	//
+	// The options take effect on that single call only.
+	Opt = CloneOpt
 
 	// CloneOpt is functional option functor for Clone()
 	CloneOpt func(c *cloner)
@@ -57,7 +63,34 @@ var (
 // exported fields of a struct in this case. The unexported fields will be ignored by
 // default ( see also cloner.IgnoreUnexportedError ) .
 //
+// The target is an exact copy of the source, the zero and nil source fields overwrite
+// the target fields too. Note that they were skipped before, pass Patch() for that.
+//
 func Clone(fromVar, toVar interface{}, opts ...CloneOpt) interface{} {
+	if err := CloneE(fromVar, toVar, opts...); err != nil {
+		log.Warnf("Clone not ok: %v", err)
+	}
+	return toVar
+}
+
+// CloneE makes a deep clone of 'from' like Clone, but returns the
+// error instead of logging it.
+//
+// The cloning goes on even if some fields failed, and all of them
+// are returned as a CloneErrors, each of them is annotated with the
+// path of the source field:
+//
+//	field "Orders[2].Price": cannot convert string to float64
+//
+// The failure kinds can be tested by errors.As:
+//
+//	var ce *ref.ConversionError
+//	if err := ref.CloneE(from, &to); errors.As(err, &ce) {
+//		...
+//	}
+//
+// See also UnsettableError, UnsupportedKindError and ConversionError.
+func CloneE(fromVar, toVar interface{}, opts ...CloneOpt) (err error) {
 	c := buildDefaultCloner()
 	for _, opt := range opts {
 		opt(&c)
@@ -67,18 +100,53 @@ func Clone(fromVar, toVar interface{}, opts ...CloneOpt) interface{} {
 		if toVar != nil {
 			ValueOf(toVar).IndirectValue().SetNil() // set the target object to zero or nil
 		}
-		return toVar
+		return
 	}
 	if toVar == nil {
-		return toVar
+		return
 	}
 
-	if err := c.Copy(fromVar, toVar); err != nil {
-		log.Warnf("Clone not ok: %v", err)
+	return c.Copy(fromVar, toVar)
+}
+
+// MustClone makes a deep clone of 'from' like Clone, but panics on
+// any error.
+func MustClone(fromVar, toVar interface{}, opts ...CloneOpt) interface{} {
+	if err := CloneE(fromVar, toVar, opts...); err != nil {
+		panic(err)
 	}
 	return toVar
 }
 
+// IgnoreFields ignores the fields with the given names, it's a short
+// form of WithIgnoredFieldNames.
+func IgnoreFields(names ...string) CloneOpt {
+	return WithIgnoredFieldNames(names...)
+}
+
+// Patch is the patch-style cloning: the nil or zero values (including
+// the zero structs, time.Time, empty strings, nil pointers, ...) in the
+// source never clobber the existing target values, even if they're
+// inside the nested structs, maps and pointers. So a partial updating
+// object (such as an API payload) can be applied to an existing object:
+//
+//	var patch = User{Nickname: "new-nick"}
+//	ref.Clone(patch, &existingUser, ref.Patch()) // only Nickname is updated
+//
+// It's a short form of WithKeepIfFromIsNilOrZero(true, true).
+func Patch() CloneOpt {
+	return WithKeepIfFromIsNilOrZero(true, true)
+}
+
+// KeepZero keeps the target values if the source values are zero,
+// such as the empty strings and the zero numbers, but the nil source
+// values are still copied, see also Patch.
+func KeepZero() CloneOpt {
+	return func(c *cloner) {
+		c.KeepIfFromIsZero = true
+	}
+}
+
 // WithIgnoredFieldNames appends the ignored name list to the Cloner operator.
 func WithIgnoredFieldNames(names ...string) CloneOpt {
 	return func(c *cloner) {
@@ -86,6 +154,40 @@ func WithIgnoredFieldNames(names ...string) CloneOpt {
 	}
 }
 
+// WithIgnoredPaths appends the ignored dotted path patterns to the
+// Cloner operator. A path is built from the source field names, for
+// example:
+//
+//	ref.Clone(user, &dto, ref.WithIgnoredPaths("Orders[*].Internal", "Profile.Password"))
+//
+// ignores the field Password of Profile, and the field Internal of
+// each element of Orders, but keeps the others even if they have the
+// same name.
+func WithIgnoredPaths(paths ...string) CloneOpt {
+	return func(c *cloner) {
+		for _, p := range paths {
+			c.ignoredPaths = append(c.ignoredPaths, compilePathPattern(p))
+		}
+	}
+}
+
+// WithPathMappings maps the source field at a dotted path pattern to
+// the named target field, for example:
+//
+//	ref.Clone(user, &dto, ref.WithPathMappings(map[string]string{
+//		"Profile.Nick":      "Nickname",
+//		"Orders[*].Cost":    "Price",
+//	}))
+//
+// The path mappings are preferred to WithNameMappings.
+func WithPathMappings(m map[string]string) CloneOpt {
+	return func(c *cloner) {
+		for k, v := range m {
+			c.pathMappings = append(c.pathMappings, pathMapping{compilePathPattern(k), v})
+		}
+	}
+}
+
 func WithNameMappings(m map[string]string) CloneOpt {
 	return func(c *cloner) {
 		c.nameMappingMap = m
@@ -110,6 +212,11 @@ func WithZeroIfEqualsFrom(b bool) CloneOpt {
 	}
 }
 
+// WithKeepIfFromIsNilOrZero specifies whether a nil or zero source
+// value keeps the target value as is, both of them are false by
+// default, so the target is an exact copy of the source.
+//
+// Passing true for both is the patch-style cloning, see Patch.
 func WithKeepIfFromIsNilOrZero(keepIfFromIsNil, keepIfFromIsZero bool) CloneOpt {
 	return func(c *cloner) {
 		c.KeepIfFromIsNil = keepIfFromIsNil
@@ -117,6 +224,41 @@ func WithKeepIfFromIsNilOrZero(keepIfFromIsNil, keepIfFromIsZero bool) CloneOpt
 	}
 }
 
+// WithCopyTagName specifies the struct tag name for reading the
+// cloning rules, the default is DefaultCopyTagName ("copy").
+// An empty name disables the struct tag rules.
+func WithCopyTagName(tagName string) CloneOpt {
+	return func(c *cloner) {
+		c.tagName = tagName
+	}
+}
+
+// WithUnexportedFields specifies whether the unexported fields should
+// be cloned deeply too, by using the unsafe helpers (see fld.go).
+// It's useful for snapshotting the domain objects with encapsulated
+// states:
+//
+//	var snapshot Document
+//	ref.Clone(&doc, &snapshot, ref.WithUnexportedFields(true))
+//
+// The unexported fields are ignored by default.
+func WithUnexportedFields(b bool) CloneOpt {
+	return func(c *cloner) {
+		c.UnexportedFields = b
+	}
+}
+
+// WithKeyTagNames specifies the struct tag names for matching the map
+// keys to the struct fields on cloning a map into a struct, the
+// default is DefaultKeyTagNames. For example:
+//
+//	ref.Clone(m, &cfg, ref.WithKeyTagNames("toml"))
+func WithKeyTagNames(tagNames ...string) CloneOpt {
+	return func(c *cloner) {
+		c.keyTagNames = tagNames
+	}
+}
+
 func WithEachFieldAlways(b bool) CloneOpt {
 	return func(c *cloner) {
 		c.EachFieldAlways = b
@@ -133,14 +275,21 @@ func buildDefaultCloner() cloner {
 		KeepIfFromIsNil:       false,
 		KeepIfFromIsZero:      false,
 		EachFieldAlways:       false,
+		tagName:               DefaultCopyTagName,
+		keyTagNames:           DefaultKeyTagNames,
+		policy:                DeepCopyPolicy,
 	}
 }
 
 // lazyCopier is a Cloner by using encoding/gob, it's just available for those exported fields.
+// The options of Copy cannot be applied, see checkBackendOptions.
 type lazyCopier struct {
 }
 
 func (c lazyCopier) Copy(fromVar, toVar interface{}, Opts ...Opt) (err error) {
+	if err = checkBackendOptions(c, Opts); err != nil {
+		return
+	}
 	buff := new(bytes.Buffer)
 	enc := gob.NewEncoder(buff)
 	dec := gob.NewDecoder(buff)
@@ -158,13 +307,91 @@ type cloner struct {
 	IgnoreUnexportedError bool
 	ZeroIfEqualsFrom      bool // 源和目标字段值相同时，目标字段被清除为未初始化的零值
 	KeepIfFromIsNil       bool // 源字段值为nil指针时，目标字段的值保持不变
-	KeepIfFromIsZero      bool // 源字段值为未初始化的零值时，目标字段的值保持不变 // 此条尚未实现
-	EachFieldAlways       bool
+	KeepIfFromIsZero      bool // 源字段值为未初始化的零值时，目标字段的值保持不变
+	EachFieldAlways       bool // 总是复制每个字段，忽略 KeepIfFromIsNil 和 KeepIfFromIsZero
+	UnexportedFields      bool // 通过 unsafe 深度复制未导出字段
+
+	policy CopyPolicy // how the reference kinds are copied, see WithCopyPolicy
+
+	backend Cloner // another Cloner does the copying if it's not nil, see WithBackend
+
+	tagName     string   // the struct tag name of cloning rules, see DefaultCopyTagName
+	keyTagNames []string // the struct tag names for matching the map keys, see DefaultKeyTagNames
+
+	ignoredPaths []pathPattern
+	pathMappings []pathMapping
+	path         string // the dotted path of the source value in copying
+
+	// visited records the source pointers (and maps) which have been
+	// cloned, so that the cycles can be terminated and the shared
+	// pointers can be kept shared in the cloned object.
+	visited map[visit]reflect.Value
+
+	errs *CloneErrors // the failed fields in copying, see cloner.fail
 }
 
+// pathMapping maps the source field at a path to the named target field
+type pathMapping struct {
+	pattern pathPattern
+	toName  string
+}
+
+// visit is a key of cloner.visited table, it is a source pointer
+// with its target type.
+// hasOptions tests whether c is configured by any option other than
+// WithBackend, that is, it differs from the default cloner.
+func (c cloner) hasOptions() bool {
+	d := buildDefaultCloner()
+	if reflect.ValueOf(c.nameMappingRule).Pointer() != reflect.ValueOf(d.nameMappingRule).Pointer() {
+		return true
+	}
+	c.nameMappingRule, d.nameMappingRule = nil, nil
+	c.backend, c.path, c.visited, c.errs = nil, "", nil, nil
+	return !reflect.DeepEqual(c, d)
+}
+
+// checkBackendOptions returns an *UnsupportedOptionError if any of opts
+// (except WithBackend) is given to the backend which cannot apply them.
+func checkBackendOptions(backend Cloner, opts []Opt) error {
+	if len(opts) == 0 {
+		return nil
+	}
+	c := buildDefaultCloner()
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.hasOptions() {
+		return &UnsupportedOptionError{Backend: backend}
+	}
+	return nil
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// Copy clones fromVar into toVar deeply, see also Clone.
+//
+// The options configure this copying only, c itself is never changed.
 func (c cloner) Copy(fromVar, toVar interface{}, Opts ...Opt) (err error) {
-	if !hasAnyValidTypes(fromVar, reflect.Struct, reflect.Ptr, reflect.Map) {
-		err = errors.New("fromVar should be a valid struct object or pointer object")
+	if len(Opts) > 0 {
+		c.ignoredNames = c.ignoredNames[:len(c.ignoredNames):len(c.ignoredNames)]
+		c.ignoredPaths = c.ignoredPaths[:len(c.ignoredPaths):len(c.ignoredPaths)]
+		c.pathMappings = c.pathMappings[:len(c.pathMappings):len(c.pathMappings)]
+		for _, opt := range Opts {
+			opt(&c)
+		}
+	}
+	if c.backend != nil {
+		if c.hasOptions() {
+			return &UnsupportedOptionError{Backend: c.backend}
+		}
+		return c.backend.Copy(fromVar, toVar)
+	}
+
+	if !hasAnyValidTypes(fromVar, reflect.Struct, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array) {
+		err = errors.New("fromVar should be a valid struct, map, slice, array or pointer object")
 		return
 	}
 	if !hasAnyValidTypes(toVar, reflect.Struct, reflect.Ptr, reflect.Map) {
@@ -188,31 +415,51 @@ func (c cloner) Copy(fromVar, toVar interface{}, Opts ...Opt) (err error) {
 	}
 
 	if !to.CanAddr() {
-		return errors.New("clone to value is unaddressable")
+		return &UnsettableError{Type: tt}
 	}
 
 	if !from.IsValid() {
-		return errors.New("clone to value is unaddressable")
+		return errors.New("clone from value is invalid")
 	}
 
 	if from.IsNil() || from.IsZero() {
 		// SetNil(to)
 		// SetZero(to)
-		to.Set(reflect.Zero(to.Type()))
+		if !c.shouldKeepTarget(from.Value, to.Value) {
+			to.Set(reflect.Zero(to.Type()))
+		}
 		return
 	}
 
+	if c.visited == nil {
+		c.visited = make(map[visit]reflect.Value)
+	}
+	if c.errs == nil {
+		c.errs = new(CloneErrors)
+	}
+	if pf, pt := fv.PtrToIndirectValueRecursive(), tv.PtrToIndirectValueRecursive(); pf.Kind() == reflect.Ptr && pt.Kind() == reflect.Ptr {
+		// the root object might be referenced by its descendants
+		c.visited[visit{pf.Pointer(), pt.Type()}] = pt.Value
+	}
+
 	fk := from.Kind()
-	if fk == reflect.Struct {
+	if copier, ok := findCopier(from.Type()); ok {
+		err = copier(from.Value, to.Value)
+	} else if fk == reflect.Struct {
 		err = c.copyStructTo(from, to, ft, tt, fv, tv)
 	} else if fk == reflect.Slice || fk == reflect.Array {
 		err = c.copySliceTo(from, to, fk, to.Kind(), ft, tt, fv, tv)
 	} else if fk == reflect.Map {
 		err = c.copyMapTo(from, to, fk, to.Kind(), ft, tt, fv, tv)
 	} else {
-		sfk, stk := ft.Kind(), tt.Kind()
-		log.Warnf("not implemented: %v (%v) -> %v (%v)", ft, sfk, tt, stk)
 		// []ref.Employee (slice) -> ref.User (struct)
+		err = &UnsupportedKindError{From: ft, To: tt}
+	}
+	if ce := c.annotate(err); ce != nil {
+		*c.errs = append(*c.errs, ce)
+	}
+	if err = nil; len(*c.errs) > 0 {
+		err = *c.errs
 	}
 	return
 }
@@ -229,90 +476,83 @@ func (c cloner) copyMapTo(from, to Value, fk, tk reflect.Kind, oft, ott reflect.
 	case reflect.Struct:
 		err = c.copyMapToStruct(from, to, fromType, toType, keyType, valType)
 	default:
-		err = errors.New("copying from %v to %v: NOT IMPLEMENT", oft, ott)
+		err = &UnsupportedKindError{From: oft, To: ott}
 	}
 
 	// err = errors.New("not implement")
 	return
 }
 
+// copyMapToStruct decodes the map entries into the fields of target
+// struct. The nested maps and slices are decoded into the nested
+// structs, pointers to struct, slices and maps of struct deeply. The
+// fields of the embedded structs are promoted as golang does.
+//
+// The keys which have no matched fields are ignored.
 func (c cloner) copyMapToStruct(from, to Value, fromType, toType, keyType, valType reflect.Type) (err error) {
-	if to.IsNil() {
-		nsi := reflect.New(toType)
-		to.Addr().Elem().Set(nsi)
-	}
-
-	var ec = errors.NewContainer("copying")
-	var stringType = reflect.TypeOf("")
-
 	for _, key := range from.MapKeys() {
-		value := from.MapIndex(key)
-		if value.CanInterface() {
-			i := value.Interface()
-			//log.Debugf("i: %v / %v. type: %v %v", i, ValueOf(i).Kind(), value.Type(), value.Kind())
-			value = reflect.ValueOf(i)
+		name := mapKeyString(key)
+		fc := c.enter(name)
+		if fc.shouldBeIgnored(name) {
+			continue
 		}
 
-		k := key.Convert(stringType)
-		name := k.String()
-		structFieldValue := to.FieldByName(name)
-
-		// TODO more target types. for example: map, slice, ptr to struct\map\slice, ...
-		if value.Kind() == reflect.Map && structFieldValue.Kind() == reflect.Struct {
-			err = c.copyMapToStruct(Value{value}, Value{structFieldValue}, value.Type(), structFieldValue.Type(), value.Type(), structFieldValue.Type())
-			return
+		sf, ok := c.lookupFieldByKey(toType, fc.targetName(name))
+		if !ok {
+			if sf, ok = c.lookupFieldByKey(toType, name); !ok {
+				continue // no such field
+			}
 		}
-
-		var ecf = errors.NewContainer("might be invalid field")
-
-	adaptedNewFieldName:
-		if !structFieldValue.IsValid() {
-			if unicode.IsLower([]rune(name)[0]) || strings.Contains(name, "-") {
-				name = strings.Title(name)
-				structFieldValue = to.FieldByName(name)
-				log.Debugf("    trying for field name %q", name)
-				ecf = errors.NewContainer("might be invalid field")
-				goto adaptedNewFieldName
+		tof, ok := fieldByIndexAlloc(to.Value, sf.Index)
+		if !ok || !tof.CanSet() {
+			if err = fc.fail(&UnsettableError{Type: sf.Type}); err != nil {
+				return
 			}
-			ecf.Attach(errors.New("no such field: %s in obj", name))
-			ec.Attach(ecf.Error())
 			continue
 		}
 
-		// If obj field value is not settable an error is thrown
-		if !structFieldValue.CanSet() {
-			if unicode.IsLower([]rune(name)[0]) || strings.Contains(name, "-") {
-				name = strings.Title(name)
-				structFieldValue = to.FieldByName(name)
-				log.Debugf("    trying field name %q", name)
-				ecf = errors.NewContainer("field")
-				goto adaptedNewFieldName
-			}
-			ecf.Attach(errors.New("cannot set %s field value", name))
-			ec.Attach(ecf.Error())
-			continue
+		if err = fc.fail(fc.copyValue(from.MapIndex(key), tof)); err != nil {
+			return
 		}
+	}
+	return
+}
 
-		structFieldType := structFieldValue.Type()
-		//val := value.Convert(structFieldType)
+// lookupFieldByKey finds the exported field of a struct type by a map
+// key, see DefaultKeyTagNames for the matching rules.
+func (c cloner) lookupFieldByKey(toType reflect.Type, key string) (sf reflect.StructField, ok bool) {
+	return keyMatcherOf(toType, c.keyTagNames).lookup(key)
+}
 
-		if !value.Type().AssignableTo(structFieldType) {
-			value, err = c.tryConvert(value, structFieldType)
-			if err != nil {
-				ec.Attach(err)
-				continue
+// mapKeyString returns the string form of a map key
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.Interface {
+		key = key.Elem()
+	}
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+// fieldByIndexAlloc returns the nested field of an addressable struct
+// like reflect.Value.FieldByIndex, but the nil embedded pointers in
+// the way will be allocated. It returns false if a nil embedded
+// pointer cannot be allocated.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
 			}
-			//ecf.Attach(errors.New("provided value type '%v' didn't match obj field type '%v'", value.Type(), structFieldType))
-			//ec.Attach(ecf.Error())
-			//continue
+			v = v.Elem()
 		}
-
-		structFieldValue.Set(value)
-		ec.Attach(ecf.Error())
+		v = v.Field(x)
 	}
-
-	err = ec.Error()
-	return
+	return v, true
 }
 
 func (c cloner) copyMapToMap(from, to Value, fromType, toType, keyType, valType reflect.Type) (err error) {
@@ -336,13 +576,200 @@ func (c cloner) copyMapToMap(from, to Value, fromType, toType, keyType, valType
 		k := key.Convert(toKeyType)
 		v := from.MapIndex(key)
 		log.Debugf("    k=%v, v=%v", k, v)
-		to.SetMapIndex(k, v.Convert(toValType))
+
+		// the existing value will be merged, or kept if v is zero
+		nv := reflect.New(toValType).Elem()
+		if old := to.MapIndex(k); old.IsValid() {
+			nv.Set(old)
+		}
+		if e := c.enterIndex(k).copyValue(v, nv); e != nil {
+			if err = c.enterIndex(k).fail(e); err != nil {
+				return
+			}
+			continue // the failed entry is not set
+		}
+		to.SetMapIndex(k, nv)
 	}
 	return
 }
 
 func (c cloner) copySliceTo(from, to Value, fk, tk reflect.Kind, oft, ott reflect.Type, ofv, otv Value) (err error) {
-	err = errors.New("not implement")
+	switch tk {
+	case reflect.Slice:
+		// slice -> slice, array -> slice: the target will be replaced
+		// with a new slice which has the same length as the source.
+		n := from.Len()
+		ns := reflect.MakeSlice(to.Type(), n, n)
+		for i := 0; i < n; i++ {
+			if err = c.enterIndex(i).fail(c.enterIndex(i).copyValue(from.Index(i), ns.Index(i))); err != nil {
+				return
+			}
+		}
+		to.Set(ns)
+	case reflect.Array:
+		// slice -> array, array -> array: the source will be truncated
+		// if it's longer than target, or the rest elements of target
+		// will be reset to zero value.
+		n, tl := from.Len(), to.Len()
+		if n > tl {
+			n = tl
+		}
+		for i := 0; i < n; i++ {
+			if err = c.enterIndex(i).fail(c.enterIndex(i).copyValue(from.Index(i), to.Index(i))); err != nil {
+				return
+			}
+		}
+		for i := n; i < tl; i++ {
+			SetZero(to.Index(i))
+		}
+	default:
+		err = &UnsupportedKindError{From: oft, To: ott}
+	}
+	return
+}
+
+// copyValue copies a single value (such as an element of slice) from
+// 'from' to the settable 'to', the nested struct, slice, map and pointer
+// will be cloned deeply.
+func (c cloner) copyValue(from, to reflect.Value) (err error) {
+	if !from.IsValid() {
+		SetZero(to)
+		return
+	}
+
+	ft, tt := from.Type(), to.Type()
+	fk, tk := ft.Kind(), tt.Kind()
+
+	if c.shouldKeepTarget(from, to) {
+		return
+	}
+
+	if copier, ok := findCopier(ft); ok {
+		return copier(from, to)
+	}
+
+	if share, skip := c.sharing(ft, tt); share {
+		to.Set(from)
+		return
+	} else if skip {
+		return
+	}
+
+	switch {
+	case fk == reflect.Interface:
+		if from.IsNil() {
+			SetZero(to)
+			return
+		}
+		return c.copyValue(from.Elem(), to)
+
+	case tk == reflect.Interface:
+		if !ft.AssignableTo(tt) {
+			return &ConversionError{From: ft, To: tt}
+		}
+		nv := reflect.New(ft).Elem()
+		if !to.IsNil() && to.Elem().Type() == ft {
+			nv.Set(to.Elem()) // merge into the existing value
+		}
+		if err = c.copyValue(from, nv); err == nil {
+			to.Set(nv)
+		}
+		return
+
+	case fk == reflect.Ptr:
+		if from.IsNil() {
+			SetZero(to)
+			return
+		}
+		if tk != reflect.Ptr {
+			return c.copyValue(from.Elem(), to)
+		}
+		key := visit{from.Pointer(), tt}
+		if v, ok := c.visited[key]; ok {
+			to.Set(v)
+			return
+		}
+		if c.KeepIfFromIsZero && !to.IsNil() && isMergeableKind(tt.Elem().Kind()) {
+			// patch the existing target object
+			if c.visited != nil {
+				c.visited[key] = to
+			}
+			return c.copyValue(from.Elem(), to.Elem())
+		}
+		nv := reflect.New(tt.Elem())
+		if c.visited != nil {
+			c.visited[key] = nv
+		}
+		if err = c.copyValue(from.Elem(), nv.Elem()); err == nil {
+			to.Set(nv)
+		}
+		return
+
+	case tk == reflect.Ptr:
+		if c.KeepIfFromIsZero && !to.IsNil() && isMergeableKind(tt.Elem().Kind()) {
+			// patch the existing target object
+			return c.copyValue(from, to.Elem())
+		}
+		nv := reflect.New(tt.Elem())
+		if err = c.copyValue(from, nv.Elem()); err == nil {
+			to.Set(nv)
+		}
+		return
+
+	case fk == reflect.Struct && tk == reflect.Struct:
+		if ft == tt && !hasExportedFields(ft) && !c.UnexportedFields {
+			// the opaque values such as time.Time, big.Int, they are
+			// copied deeply only if WithUnexportedFields(true)
+			to.Set(from)
+			return
+		}
+		return c.copyStructTo(Value{from}, Value{to}, ft, tt, Value{from}, Value{to.Addr()})
+
+	case (fk == reflect.Slice || fk == reflect.Array) && (tk == reflect.Slice || tk == reflect.Array):
+		if fk == reflect.Slice && from.IsNil() {
+			SetZero(to)
+			return
+		}
+		return c.copySliceTo(Value{from}, Value{to}, fk, tk, ft, tt, Value{from}, Value{to})
+
+	case fk == reflect.Map && tk == reflect.Map:
+		if from.IsNil() {
+			SetZero(to)
+			return
+		}
+		key := visit{from.Pointer(), tt}
+		if v, ok := c.visited[key]; ok {
+			to.Set(v)
+			return
+		}
+		nm := to
+		if !c.KeepIfFromIsZero || nm.IsNil() {
+			nm = reflect.MakeMap(tt)
+			to.Set(nm)
+		}
+		if c.visited != nil {
+			c.visited[key] = nm
+		}
+		return c.copyMapTo(Value{from}, Value{to}, fk, tk, ft, tt, Value{from}, Value{to})
+
+	case fk == reflect.Map && tk == reflect.Struct:
+		if from.IsNil() {
+			SetZero(to)
+			return
+		}
+		return c.copyMapToStruct(Value{from}, Value{to}, ft, tt, ft.Key(), ft.Elem())
+
+	case ft.AssignableTo(tt):
+		to.Set(from)
+		return
+
+	}
+
+	// the scalars, such as int -> string, "12" -> int8, float64 -> int
+	var out reflect.Value
+	if out, err = c.tryConvert(from, tt); err == nil {
+		to.Set(out)
+	}
 	return
 }
 
@@ -375,109 +802,198 @@ func (c cloner) copyStructTo(from, to Value, oft, ott reflect.Type, ofv, otv Val
 		//baseType := to.Type().Elem()
 		//newTargetType, newToOrig, newTo := c.indirectCreate(baseType)
 		//log.Debugf(" copying struct to slice[0]: srcV=%v, tgtV=%v (baseType=%v, newTo.Type=%v, newToOrig.Type=%v)", fromType, newTargetType, baseType, newTo.Type(), newToOrig.Type())
+		if to.Type().Key().Kind() != reflect.String {
+			err = &UnsupportedKindError{From: oft, To: to.Type()}
+			return
+		}
 		h = newHeldMap(to.Value)
 	} else if toKind == reflect.Struct {
 		h = newHeldStruct(to.Value)
 	} else {
-		err = errors.New("cannot copy struct (%v) to %v", oft, ott.Elem())
+		err = &UnsupportedKindError{From: oft, To: to.Type()}
 		return
 	}
 
-	fieldsCount := fromType.NumField()
-	for i := 0; i < fieldsCount; i++ {
-		field := fromType.Field(i)
-		if c.shouldBeIgnored(field.Name) {
+	plan := c.planOf(fromType, to.Type())
+	for i := range plan.fields {
+		pf := &plan.fields[i]
+		field := pf.field
+		fc := c.enter(field.Name)
+		if fc.shouldBeIgnored(field.Name) {
 			continue
 		}
-		if !isExportableField(field) {
-			toName := c.targetName(field.Name)
-			vov := from.Field(i)
-			tof := to.FieldByName(toName)
-			if tof.CanSet() {
-				tof.Set(vov)
-			} else if !c.IgnoreUnexportedError {
-				err = errors.New("target cannot be set: field %q (value: %v)", field.Name, vov.Interface())
+		if field.Anonymous && pf.exported {
+			// the promoted fields have the same path as their owner
+			fieldValue := from.Field(pf.index)
+			if err = c.copyStructTo(Value{fieldValue}, to, oft, ott, ofv, otv); err != nil {
+				// err = errors.New("nested structure on field %q", field.Name)
 				return
 			}
 			continue
 		}
-		if field.Anonymous {
-			fieldValue := from.Field(i)
-			if err = c.copyStructTo(Value{fieldValue}, to, oft, ott, ofv, otv); err != nil {
-				// err = errors.New("nested structure on field %q", field.Name)
+
+		c := fc
+		if !pf.exported && c.UnexportedFields && toKind == reflect.Struct {
+			if pf.rules.skip {
+				continue
+			}
+			if !from.CanAddr() {
+				// the unexported fields can be accessed by an addressable value only
+				tmp := reflect.New(fromType).Elem()
+				tmp.Set(from.Value)
+				from = Value{tmp}
+			}
+			if err = c.fail(c.copyUnexportedField(from.Field(pf.index), to.Value, c.targetName(field.Name))); err != nil {
 				return
 			}
 			continue
 		}
+		if !pf.exported {
+			toName := c.targetName(field.Name)
+			vov := from.Field(pf.index)
+			tof := to.FieldByName(toName)
+			if tof.CanSet() {
+				tof.Set(vov)
+			} else if !c.IgnoreUnexportedError {
+				if err = c.fail(&UnsettableError{Type: field.Type}); err != nil {
+					return
+				}
+			}
+			continue
+		}
 
 		var (
 			tot    reflect.Type
-			vov    = from.Field(i)
-			toName = c.targetName(field.Name)
-			tof    = h.Get(field, toName)
-			// tof := to.FieldByName(toName)
+			tof    reflect.Value
+			vov    = from.Field(pf.index)
+			target = pf.target
 		)
+		if pf.rules.skip {
+			continue
+		}
+		if toName := c.targetName(field.Name); toName != field.Name {
+			// mapped by the call-site options, resolve it dynamically
+			target = planTarget{name: toName, rules: pf.rules, methodIndex: -1}
+			if toKind == reflect.Struct {
+				target = c.resolveTarget(to.Type(), toName, pf.rules)
+			}
+		}
+		if target.rules.skip {
+			continue
+		}
+		if target.rules.omitEmpty && IsZero(vov) {
+			continue // keep the target field if the source is zero
+		}
+
 		if toKind == reflect.Struct {
-			if ttf, ok := to.Type().FieldByName(toName); ok {
-				tot = ttf.Type
-				// log.Debugf("  | ttf: %v | tt: %v %v | tof.IsValid: %v } to: %v", ttf, tt.Kind(), tt, tof.IsValid(), to.Type())
+			if !target.found() {
+				continue // the target field not exists, ignore it and go to next field
+			}
+			if target.isField {
+				tof, tot = h.(*heldStruct).GetByIndex(field, target.field), target.field.Type
 			} else {
-				for z := otv.Value; z.IsValid(); {
-					tm := z.MethodByName(toName)
-					if tm.IsValid() {
-						tof, tot = tm, tm.Type()
-						break
-					}
-					if z.Kind() != reflect.Ptr {
-						break
-					}
-					z = z.Elem()
+				if to.CanAddr() {
+					tof = to.Addr().Method(target.methodIndex)
+				} else if tof = to.MethodByName(target.name); !tof.IsValid() {
+					continue // a pointer receiver method cannot be invoked
 				}
-				if tot == nil || tot.Kind() == reflect.Invalid {
-					if !tof.IsValid() {
-						continue // the target field not exists, ignore it and go to next field
-					}
-
-					log.Debugf("  | tof.IsValid: %v", tof.IsValid())
-					tot = tof.Type()
+				tot = tof.Type()
+			}
+		} else {
+			// copy into a temporary value and set it as the map entry
+			nv := reflect.New(to.Type().Elem()).Elem()
+			if old := h.Get(field, target.name); old.IsValid() {
+				nv.Set(old)
+			}
+			if e := c.copyValue(vov, nv); e != nil {
+				if err = c.fail(e); err != nil {
+					return
 				}
+				continue
 			}
+			h.Set(nv)
+			continue
 		}
 		h.SetTargetField(tof)
 
+		if (target.rules.deep || target.rules.shallow) && tot != nil && tof.Kind() != reflect.Func {
+			if err = c.fail(c.copyFieldByRules(vov, h, target.rules, field.Type, tot, field.Name, target.name)); err != nil {
+				return
+			}
+			continue
+		}
+
 		// fk, tk := from.Kind(), to.Kind()
-		if err = c.copyFieldToField(vov, h, field.Type, tot, field.Name, toName); err != nil {
+		if err = c.fail(c.copyFieldToField(vov, h, field.Type, tot, field.Name, target.name)); err != nil {
 			return
 		}
 	}
 
-	methodCount := fromType.NumMethod()
-	for i := 0; i < methodCount; i++ {
-		method := fromType.Method(i)
+	for i := range plan.methods {
+		pm := &plan.methods[i]
+		method := pm.method
+		c := c.enter(method.Name)
 		if c.shouldBeIgnored(method.Name) {
 			continue
 		}
-		if (method.Type.NumIn() != 0 && method.Type.NumIn() != 1) || method.Type.NumOut() != 1 {
-			// log.Debugf("  -> func %q : %v. in: %v, out: %v", method.Name, method.Type, method.Type.NumIn(), method.Type.NumOut())
+
+		target := pm.target
+		if toName := c.targetName(method.Name); toName != method.Name {
+			target = planTarget{name: toName, methodIndex: -1}
+			if ttf, ok := to.Type().FieldByName(toName); ok {
+				target.field, target.isField, target.rules = ttf, true, c.fieldTag(ttf)
+			}
+		}
+		if !target.isField || target.rules.skip || c.shouldBeIgnored(target.name) {
 			continue
 		}
-		if !isExportableMethod(method) {
+		tof, ok := fieldByIndexAlloc(to.Value, target.field.Index)
+		if !ok || !tof.CanSet() {
 			continue
 		}
-
-		toName := c.targetName(method.Name)
-		if _, ok := to.Type().FieldByName(toName); ok {
-			// log.Debugf("  -> func %q -> field totf: %v", method.Name, totf)
-			tof := to.FieldByName(toName)
-			// log.Debugf("  -> func %q -> field tof: %v", method.Name, tof)
-			vom := from.Method(i)
-			out := vom.Call([]reflect.Value{})
-			tof.Set(out[0])
+		if !from.CanAddr() {
+			// the pointer receiver getters can be invoked on an addressable value only
+			tmp := reflect.New(fromType).Elem()
+			tmp.Set(from.Value)
+			from = Value{tmp}
+		}
+		if err = c.fail(c.copyGetterToField(from.Addr().Method(method.Index), method.Name, pm.withErr, tof)); err != nil {
+			return
 		}
 	}
 	return
 }
 
+// copyGetterToField invokes a getter X() or GetX(), and copies its
+// result to the target field. The error returned by the getter
+// aborts the cloning.
+func (c cloner) copyGetterToField(getter reflect.Value, name string, withErr bool, tof reflect.Value) (err error) {
+	var out []reflect.Value
+	if out, err = callMethod(getter, nil); err != nil {
+		return
+	}
+	if withErr && !out[1].IsNil() {
+		return c.abort(&MethodError{Method: name, Err: out[1].Interface().(error)})
+	}
+	return c.copyValue(out[0], tof)
+}
+
+// callMethod calls a method, the panic is returned as an error.
+func callMethod(method reflect.Value, in []reflect.Value) (out []reflect.Value, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if e2, ok := e.(error); ok {
+				err = e2
+			} else {
+				err = errors.New("%v", e)
+			}
+		}
+	}()
+
+	out = method.Call(in)
+	return
+}
+
 func (c cloner) copyCloneableObject(z Cloneable, to Value, fromVar, toVar interface{}) (err error) {
 	defer func() {
 		if e := recover(); e != nil {
@@ -494,14 +1010,19 @@ func (c cloner) copyCloneableObject(z Cloneable, to Value, fromVar, toVar interf
 		}
 	}()
 
-	newI := z.Clone()
-	var too, tol reflect.Value
-	for too = to.Value; too.Kind() == reflect.Ptr; {
-		tol = too
-		too = too.Elem()
+	newI := reflect.ValueOf(z.Clone())
+	for too := to.Value; too.Kind() == reflect.Ptr && !too.IsNil(); too = too.Elem() {
+		// such as: Clone() returns *U, and toVar is **U or *U
+		if tt := too.Type().Elem(); newI.Type().AssignableTo(tt) {
+			too.Elem().Set(newI)
+			return
+		} else if newI.Kind() == reflect.Ptr && newI.Type().Elem().AssignableTo(tt) {
+			too.Elem().Set(newI.Elem())
+			return
+		}
 	}
-	tol.Set(reflect.ValueOf(newI))
 	// to.IndirectValueRecursive().Set(reflect.ValueOf(newI))
+	err = &ConversionError{From: newI.Type(), To: to.Type()}
 	return
 }
 
@@ -533,6 +1054,15 @@ func (c cloner) copyFieldToField(fromField reflect.Value, to held, oft, ott refl
 		}
 	}()
 
+	if fk != reflect.Func && c.shouldKeepTarget(fromField, toField) {
+		return // patch-style: keep the target field if source is nil or zero
+	}
+
+	if copier, ok := findCopier(oft); ok && tk != reflect.Func {
+		err = c.copyByCopier(copier, fromField, to, ott)
+		return
+	}
+
 	if fk == reflect.Func {
 		// func -> any
 		// if fromName == "Role" {
@@ -542,6 +1072,8 @@ func (c cloner) copyFieldToField(fromField reflect.Value, to held, oft, ott refl
 			// func toField field
 			// log.Debugf("1.1 %v -> %v", oft, ott)
 			err = c.copyFuncToField(oft, ott, fromName, toName, fromField, toField)
+		} else if share, _ := c.sharing(oft, ott); share {
+			to.Set(fromField)
 		}
 		return
 
@@ -585,13 +1117,24 @@ func (c cloner) copyFieldToField(fromField reflect.Value, to held, oft, ott refl
 			//	typ = typ.Elem()
 			// }
 			toV := reflect.New(typ)
-			toV.Elem().Set(fromField)
-			toField.Set(toV)
+			if err = c.copyValue(fromField, toV.Elem()); err == nil {
+				toField.Set(toV)
+			}
 			// log.Debugf("toV: %v (%v) = %v / %v", toField.Type().Name(), toField.Type(), toField.Pointer(), toField.Elem().Interface())
+		} else if share, _ := c.sharing(oft, ott); share {
+			to.Set(fromField)
 		} else if fk == reflect.Ptr {
-			toField.Set(fromField)
+			// clone the pointee rather than share it with source, the
+			// visited table keeps the identities and breaks the cycles.
+			nv := reflect.New(ott).Elem()
+			if toField.IsValid() && toField.Type() == ott {
+				nv.Set(toField)
+			}
+			if err = c.copyValue(fromField, nv); err == nil {
+				to.Set(nv)
+			}
 		} else {
-			err = errors.New("??? ??? error on setting %q (%v) -> %q (%v).", fromName, oft, toName, ott)
+			err = &UnsupportedKindError{From: oft, To: ott}
 			// toField.IndirectValueRecursive().Set(fromField)
 		}
 		return
@@ -654,50 +1197,113 @@ func (c cloner) copyFieldToField(fromField reflect.Value, to held, oft, ott refl
 		if needReset := c.needReset(fromField, to); needReset {
 			SetZero(toField)
 		} else if canCopy, isNilOrZeroSkipped, cannotAssignTo := c.canCopy(fromField, to, oft, ott); canCopy || isNilOrZeroSkipped {
-			if canCopy {
+			if canCopy && fk == reflect.Struct && tk == reflect.Struct {
+				err = c.copyFieldDeeply(fromField, to, oft, ott, fromName, toName)
+			} else if canCopy && (fk == reflect.Slice || fk == reflect.Array) && c.hasPathRules() {
+				// walk into the elements so that the path rules can be applied
+				err = c.copyFieldDeeply(fromField, to, oft, ott, fromName, toName)
+			} else if canCopy && fk == reflect.Map && c.KeepIfFromIsZero && !toField.IsNil() {
+				// patch the entries into the existing map
+				err = c.copyFieldDeeply(fromField, to, oft, ott, fromName, toName)
+			} else if share, skip := c.sharing(oft, ott); canCopy && !skip && (share || !holdsReferences(fk)) {
 				// toField.Set(fromField)
 				to.Set(fromField)
+			} else if canCopy && !skip {
+				// clone the maps, slices, ... by the copy policy
+				nv := reflect.New(ott).Elem()
+				if err = c.copyValue(fromField, nv); err == nil {
+					to.Set(nv)
+				}
 			} // else if isNilOrZeroSkipped { // nothing needed toField do }
 		} else if cannotAssignTo {
-			if (fk == reflect.Slice || fk == reflect.Array) && (tk == reflect.Slice || tk == reflect.Array) {
-				if oft.Elem() == ott.Elem() {
-					// such as: [2]string -> [3]string
-					if oft.Len() < ott.Len() {
-						for i := 0; i < fromField.Len(); i++ {
-							toField.Index(i).Set(fromField.Index(i))
-						}
-					}
-				} else {
-					// such as: []int => []string
-					err = errors.New("error on setting %q (%v) -> %q (%v): TODO: such as: []int => []string", fromName, oft, toName, ott)
+			if fk == reflect.Struct && tk == reflect.Struct {
+				// such as: Address -> AddressDto
+				err = c.copyFieldDeeply(fromField, to, oft, ott, fromName, toName)
+			} else if (fk == reflect.Slice || fk == reflect.Array) && (tk == reflect.Slice || tk == reflect.Array) {
+				// such as: [2]string -> [3]string, []Employee -> []User
+				nv := reflect.New(ott).Elem()
+				if err = c.copyValue(fromField, nv); err == nil {
+					to.Set(nv)
 				}
-			} else if isKindInt(fk) && isKindInt(tk) {
-				i := fromField.Int()
-				toField.SetInt(i)
-			} else if isKindFloat(fk) && isKindFloat(tk) {
-				i := fromField.Float()
-				toField.SetFloat(i)
-			} else if isKindComplex(fk) && isKindComplex(tk) {
-				i := fromField.Complex()
-				toField.SetComplex(i)
 			} else {
-				err = errors.New("error on setting %q (%v) -> %q (%v): canCopy=%v|isNilOrZeroSkipped=%v|cannotAssignTo=%v, needReset=%v", fromName, oft, toName, ott, canCopy, isNilOrZeroSkipped, cannotAssignTo, needReset)
+				// such as: int -> string, "12" -> int8, float64 -> int
+				var out reflect.Value
+				if out, err = c.tryConvert(fromField, ott); err == nil {
+					to.Set(out)
+				}
 			}
 		} else {
-			err = errors.New("??? ??? error on setting %q (%v) -> %q (%v): canCopy=%v|isNilOrZeroSkipped=%v|cannotAssignTo=%v, needReset=%v", fromName, oft, toName, ott, canCopy, isNilOrZeroSkipped, cannotAssignTo, needReset)
+			err = &UnsupportedKindError{From: oft, To: ott}
 		}
 	} else if tk == reflect.Ptr {
 		// any (non-ptr) -> ptr
 		toField = IndirectValue(toField)
 	} else {
-		var fv, tv interface{}
-		if fromField.CanInterface() {
-			fv = fromField.Interface()
-		}
-		if toField.CanInterface() {
-			tv = toField.Interface()
-		}
-		err = errors.New("target cannot be set: field %q (%v) -> %q (%v) (value: src=%v, tgt=%v)", fromName, oft, toName, ott, fv, tv)
+		err = &UnsettableError{Type: ott}
+	}
+	return
+}
+
+// copyUnexportedField copies an unexported field deeply through the
+// unsafe helpers, see WithUnexportedFields.
+func (c cloner) copyUnexportedField(fromField, to reflect.Value, toName string) (err error) {
+	tof := to.FieldByName(toName)
+	if !tof.IsValid() {
+		return // the target field not exists
+	}
+	if !to.CanAddr() {
+		return &UnsettableError{Type: tof.Type()}
+	}
+	return c.copyValue(accessibleField(fromField), accessibleField(tof))
+}
+
+// copyFieldDeeply copies a nested struct (or slice) deeply, so that
+// the rules (such as ignored paths) can be applied to its fields.
+func (c cloner) copyFieldDeeply(fromField reflect.Value, to held, oft, ott reflect.Type, fromName, toName string) (err error) {
+	nv := reflect.New(ott).Elem()
+	if tof := to.TargetField(); tof.IsValid() && tof.Type() == ott {
+		nv.Set(tof) // keep the fields which are not copied
+	}
+	if err = c.copyValue(fromField, nv); err == nil {
+		to.Set(nv)
+	}
+	return
+}
+
+// copyFieldByRules copies a field deeply or shallowly as its struct
+// tag rules required.
+func (c cloner) copyFieldByRules(fromField reflect.Value, to held, rules copyTag, oft, ott reflect.Type, fromName, toName string) (err error) {
+	if rules.shallow && oft.AssignableTo(ott) {
+		to.Set(fromField)
+		return
+	}
+	if rules.shallow {
+		return c.copyFieldToField(fromField, to, oft, ott, fromName, toName)
+	}
+
+	nv := reflect.New(ott).Elem()
+	if err = c.copyValue(fromField, nv); err == nil {
+		to.Set(nv)
+	}
+	return
+}
+
+// copyByCopier invokes a registered copier, the target will be set
+// through a temporary value if it's not settable (such as a map item).
+func (c cloner) copyByCopier(copier CopierFunc, fromField reflect.Value, to held, ott reflect.Type) (err error) {
+	toField := to.TargetField()
+	if toField.CanSet() {
+		return copier(fromField, toField)
+	}
+	if ott == nil {
+		ott = fromField.Type()
+	}
+	nv := reflect.New(ott).Elem()
+	if toField.IsValid() && toField.Type().AssignableTo(ott) {
+		nv.Set(toField)
+	}
+	if err = copier(fromField, nv); err == nil {
+		to.Set(nv)
 	}
 	return
 }
@@ -710,9 +1316,23 @@ func (c cloner) copyFuncToField(ft, tt reflect.Type, fromName, toName string, fr
 	return
 }
 
+// copyFieldToFunc invokes a setter X(v) or SetX(v) of the target with
+// the source field, the error returned by the setter aborts the
+// cloning.
 func (c cloner) copyFieldToFunc(ft, tt reflect.Type, fromName, toName string, fromField, toField reflect.Value) (err error) {
-	if tt.NumIn() == 1 {
-		_ = toField.Call([]reflect.Value{fromField})
+	if tt.NumIn() != 1 {
+		return
+	}
+	arg := reflect.New(tt.In(0)).Elem()
+	if err = c.copyValue(fromField, arg); err != nil {
+		return
+	}
+	var out []reflect.Value
+	if out, err = callMethod(toField, []reflect.Value{arg}); err != nil {
+		return
+	}
+	if len(out) == 1 && out[0].Type() == errorType && !out[0].IsNil() {
+		err = c.abort(&MethodError{Method: toName, Err: out[0].Interface().(error)})
 	}
 	return
 }
@@ -728,20 +1348,10 @@ func (c cloner) canCopy(from reflect.Value, to held, fromType, toType reflect.Ty
 		return
 	}
 	if fromType.AssignableTo(toType) {
-		if c.EachFieldAlways {
+		if c.shouldKeepTarget(from, tof) {
+			isNilOrZeroSkipped = true
+		} else {
 			canCopy = true
-		} else if CanIsNil(from) {
-			if IsNil(from) {
-				canCopy, isNilOrZeroSkipped = c.KeepIfFromIsNil, true
-			} else {
-				canCopy = true
-			}
-		} else if CanIsZero(from) {
-			if IsZero(from) {
-				canCopy, isNilOrZeroSkipped = c.KeepIfFromIsZero, true
-			} else {
-				canCopy = true
-			}
 		}
 	} else {
 		cannotAssignTo = true
@@ -749,21 +1359,52 @@ func (c cloner) canCopy(from reflect.Value, to held, fromType, toType reflect.Ty
 	return
 }
 
+// shouldKeepTarget reports whether the existing target value should be
+// kept as is since the source value is nil or zero, see also
+// WithKeepIfFromIsNilOrZero.
+func (c cloner) shouldKeepTarget(from, to reflect.Value) bool {
+	if c.EachFieldAlways || !from.IsValid() || !to.IsValid() || IsZeroSafe(to) {
+		return false
+	}
+	if CanIsNil(from) && from.IsNil() {
+		return c.KeepIfFromIsNil
+	}
+	return c.KeepIfFromIsZero && IsZeroSafe(from)
+}
+
 func (c cloner) needReset(from reflect.Value, to held) (needReset bool) {
 	tof := to.TargetField()
 	if from.Kind() != tof.Kind() {
 		return
 	}
-	if Equal(from, tof) {
-		needReset = c.ZeroIfEqualsFrom
+	if c.ZeroIfEqualsFrom && Equal(from, tof) {
+		needReset = true
 	}
 	return
 }
 
+// enter returns a copy of cloner for copying the named field
+func (c cloner) enter(name string) cloner {
+	c.path = joinPath(c.path, name)
+	return c
+}
+
+// enterIndex returns a copy of cloner for copying an element of
+// slice, array or map
+func (c cloner) enterIndex(index interface{}) cloner {
+	c.path = indexPath(c.path, index)
+	return c
+}
+
 func (c cloner) targetName(fromName string) (toName string) {
 	var mapped bool
 	var toNameTmp string
 	toName = fromName
+	for _, pm := range c.pathMappings {
+		if pm.pattern.Match(c.path) {
+			return pm.toName
+		}
+	}
 	if c.nameMappingRule != nil {
 		toNameTmp, mapped = c.nameMappingRule(fromName)
 		if mapped {
@@ -778,11 +1419,21 @@ func (c cloner) targetName(fromName string) (toName string) {
 	return
 }
 
+func (c cloner) hasPathRules() bool {
+	return len(c.ignoredPaths) > 0 || len(c.pathMappings) > 0
+}
+
 func (c cloner) shouldBeIgnored(name string) (ignored bool) {
 	for _, n := range c.ignoredNames {
 		if name == n {
 			ignored = true
-			break
+			return
+		}
+	}
+	for _, p := range c.ignoredPaths {
+		if p.Match(c.path) {
+			ignored = true
+			return
 		}
 	}
 	return
@@ -813,18 +1464,7 @@ func (c cloner) Captalize(s string) string {
 }
 
 func (c cloner) tryConvert(v reflect.Value, t reflect.Type) (out reflect.Value, err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			if e2, ok := e.(error); ok {
-				err = e2
-			} else {
-				err = errors.New("%v", e)
-			}
-		}
-	}()
-
-	out = v.Convert(t)
-	return
+	return tryConvert(v, t)
 }
 
 func (c cloner) indirectCreate(fromType reflect.Type) (newTargetType reflect.Type, parent, newTo Value) {
@@ -4,6 +4,7 @@ import (
 	"github.com/hedzr/assert"
 	"gopkg.in/hedzr/errors.v2"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 	"unsafe"
@@ -114,11 +115,19 @@ func testDefaultClone_copyCov(t *testing.T) {
 func testDefaultClone_copyTwoStruct(t *testing.T) {
 	user := User{Name: "Real Faked"}
 	userTo := User{Name: "Faked", Role: "NN"}
-	DefaultCloner.Copy(&user, &userTo)
+	DefaultCloner.Copy(&user, &userTo, Patch())
 	t.Log(userTo)
 	if userTo.Name != user.Name || userTo.Role != "NN" {
 		t.Fatal("wrong")
 	}
+
+	// BREAKING: the zero source fields were skipped by default, as
+	// Patch() does, but they're copied now
+	userTo = User{Name: "Faked", Role: "NN"}
+	DefaultCloner.Copy(&user, &userTo)
+	if userTo.Name != user.Name || userTo.Role != "" {
+		t.Fatal("wrong")
+	}
 }
 
 func testDefaultClone_copyStruct(t *testing.T) {
@@ -515,3 +524,943 @@ func testMap_cloneFromStruct(t *testing.T) {
 	Clone(s, &m1)
 	t.Logf("copy struct to map: result = %v", m1)
 }
+
+func TestCloneSlice(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("Slice: slice to slice", testSlice_sliceToSlice)
+	t.Run("Slice: slice to slice with conversion", testSlice_sliceToSliceConversion)
+	t.Run("Slice: array to slice", testSlice_arrayToSlice)
+	t.Run("Slice: slice to array", testSlice_sliceToArray)
+	t.Run("Slice: pointer elements", testSlice_ptrElements)
+}
+
+func testSlice_sliceToSlice(t *testing.T) {
+	users := []User{user1, {Name: "Alice", Role: "admin", Notes: []string{"x"}}}
+	var out []User
+	if err := DefaultCloner.Copy(users, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, user1.Name, out[0].Name)
+	assert.Equal(t, user1.Ro, out[0].Ro)
+	assert.Equal(t, "admin", out[1].Role)
+	assert.Equal(t, []string{"x"}, out[1].Notes)
+}
+
+func testSlice_sliceToSliceConversion(t *testing.T) {
+	users := []User{user1, {Name: "Alice", Nickname: "ali", Role: "admin", Age: 9}}
+	var employees []Employee
+	Clone(users, &employees)
+	assert.Equal(t, 2, len(employees))
+	for i := range users {
+		checkEmployee(employees[i], users[i], t, "Copy From Slice To Slice")
+	}
+
+	var i64s []int64
+	Clone([]int{1, 2, 3}, &i64s)
+	assert.Equal(t, []int64{1, 2, 3}, i64s)
+}
+
+func testSlice_arrayToSlice(t *testing.T) {
+	var out = []int{9, 9, 9, 9, 9}
+	if err := DefaultCloner.Copy(&[3]int{1, 2, 3}, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, []int{1, 2, 3}, out)
+}
+
+func testSlice_sliceToArray(t *testing.T) {
+	var truncated [2]int
+	if err := DefaultCloner.Copy([]int{1, 2, 3}, &truncated); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, [2]int{1, 2}, truncated)
+
+	var padded = [3]string{"a", "b", "c"}
+	if err := DefaultCloner.Copy([]string{"x"}, &padded); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, [3]string{"x", "", ""}, padded)
+}
+
+func testSlice_ptrElements(t *testing.T) {
+	u := &User{Name: "Bob", Nickname: "bob"}
+	var out []User
+	if err := DefaultCloner.Copy([]*User{u, nil}, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, "Bob", out[0].Name)
+	assert.Equal(t, "", out[1].Name)
+
+	var ptrs []*User
+	if err := DefaultCloner.Copy([]*User{u}, &ptrs); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ptrs[0] == u {
+		t.Fatal("the element should be cloned rather than shared")
+	}
+	assert.Equal(t, "bob", ptrs[0].Nickname)
+}
+
+type cyclicNode struct {
+	Value  string
+	Parent *cyclicNode
+	Prev   *cyclicNode
+	Next   *cyclicNode
+	Shared *User
+	Alias  *User
+}
+
+func TestCloneCycles(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("Cycle: self reference", testCycle_selfReference)
+	t.Run("Cycle: doubly linked list", testCycle_doublyLinkedList)
+	t.Run("Cycle: shared pointers", testCycle_sharedPointers)
+}
+
+func testCycle_selfReference(t *testing.T) {
+	a := &cyclicNode{Value: "a"}
+	a.Parent = a
+
+	var b cyclicNode
+	if err := DefaultCloner.Copy(a, &b); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "a", b.Value)
+	if b.Parent != &b {
+		t.Fatalf("the self reference should point to the clone, but got %p (want %p)", b.Parent, &b)
+	}
+}
+
+func testCycle_doublyLinkedList(t *testing.T) {
+	n1, n2, n3 := &cyclicNode{Value: "1"}, &cyclicNode{Value: "2"}, &cyclicNode{Value: "3"}
+	n1.Next, n2.Next, n3.Next = n2, n3, n1
+	n1.Prev, n2.Prev, n3.Prev = n3, n1, n2
+
+	var c1 cyclicNode
+	if err := DefaultCloner.Copy(n1, &c1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	c2, c3 := c1.Next, c1.Next.Next
+	assert.Equal(t, "2", c2.Value)
+	assert.Equal(t, "3", c3.Value)
+	if c2 == n2 || c3 == n3 {
+		t.Fatal("the nodes should be cloned rather than aliased to the originals")
+	}
+	if c3.Next != &c1 || c1.Prev != c3 || c2.Prev != &c1 || c3.Prev != c2 {
+		t.Fatal("the links between the cloned nodes are broken")
+	}
+}
+
+func testCycle_sharedPointers(t *testing.T) {
+	u := &User{Name: "shared"}
+	a := &cyclicNode{Value: "a", Shared: u, Alias: u}
+
+	var b cyclicNode
+	if err := DefaultCloner.Copy(a, &b); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if b.Shared == u {
+		t.Fatal("the shared pointer should be cloned rather than aliased to the original")
+	}
+	if b.Shared != b.Alias {
+		t.Fatal("a pointer shared twice in the source should be shared in the clone too")
+	}
+	assert.Equal(t, "shared", b.Alias.Name)
+}
+
+type taggedSource struct {
+	ID       int64  `copy:"-"`
+	FullName string `copy:"name=Name"`
+	Nickname string `copy:"omitempty"`
+	Notes    []string
+	Tags     []string `copy:"deep"`
+	Owner    *User    `copy:"shallow"`
+	Secret   string
+	Remark   string `mycopy:"-"`
+}
+
+type taggedTarget struct {
+	ID       int64
+	Name     string
+	Nickname string
+	Notes    []string
+	Tags     []string
+	Owner    *User
+	Secret   string `copy:"-"`
+	Comment  string `copy:"name=Remark"`
+	Remark   string
+}
+
+func TestCloneTags(t *testing.T) {
+	defer initLogger(t)()
+
+	owner := &User{Name: "owner"}
+	src := taggedSource{
+		ID:       9,
+		FullName: "John Doe",
+		Notes:    []string{"a"},
+		Tags:     []string{"x", "y"},
+		Owner:    owner,
+		Secret:   "s3cr3t",
+		Remark:   "remark",
+	}
+	tgt := taggedTarget{ID: 1, Nickname: "jd", Secret: "kept"}
+
+	if err := DefaultCloner.Copy(&src, &tgt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	assert.Equal(t, int64(1), tgt.ID)
+	assert.Equal(t, "John Doe", tgt.Name)
+	assert.Equal(t, "jd", tgt.Nickname)
+	assert.Equal(t, "kept", tgt.Secret)
+	assert.Equal(t, "remark", tgt.Comment)
+	assert.Equal(t, "", tgt.Remark)
+	assert.Equal(t, []string{"x", "y"}, tgt.Tags)
+	if &tgt.Tags[0] == &src.Tags[0] {
+		t.Fatal("the field tagged with 'deep' should be cloned deeply")
+	}
+	if tgt.Owner != owner {
+		t.Fatal("the field tagged with 'shallow' should be shared with the source")
+	}
+
+	// switch to another tag name
+	tgt2 := taggedTarget{ID: 1}
+	Clone(&src, &tgt2, WithCopyTagName("mycopy"))
+	assert.Equal(t, int64(9), tgt2.ID)
+	assert.Equal(t, "", tgt2.Name)
+	assert.Equal(t, "s3cr3t", tgt2.Secret)
+	assert.Equal(t, "", tgt2.Remark)
+}
+
+type pathAddress struct {
+	ID     int
+	City   string
+	Street string
+}
+
+type pathOrder struct {
+	ID       int
+	Cost     float64
+	Internal string
+}
+
+type pathProfile struct {
+	Nick     string
+	Password string
+}
+
+type pathUser struct {
+	ID      int
+	Address pathAddress
+	Profile *pathProfile
+	Orders  []pathOrder
+}
+
+type pathOrderDto struct {
+	ID       int
+	Price    float64
+	Internal string
+}
+
+type pathProfileDto struct {
+	Nickname string
+	Password string
+}
+
+type pathUserDto struct {
+	ID      int
+	Address pathAddress
+	Profile *pathProfileDto
+	Orders  []pathOrderDto
+}
+
+func TestClonePaths(t *testing.T) {
+	defer initLogger(t)()
+
+	src := &pathUser{
+		ID:      1,
+		Address: pathAddress{ID: 2, City: "Berlin", Street: "Main"},
+		Profile: &pathProfile{Nick: "neo", Password: "matrix"},
+		Orders:  []pathOrder{{ID: 3, Cost: 9.5, Internal: "x"}, {ID: 4, Cost: 1, Internal: "y"}},
+	}
+
+	var u pathUser
+	Clone(src, &u, WithIgnoredPaths("Address.ID", "Profile.Password", "Orders[*].Internal"))
+	assert.Equal(t, 1, u.ID)
+	assert.Equal(t, 0, u.Address.ID)
+	assert.Equal(t, "Berlin", u.Address.City)
+	assert.Equal(t, "neo", u.Profile.Nick)
+	assert.Equal(t, "", u.Profile.Password)
+	assert.Equal(t, 2, len(u.Orders))
+	assert.Equal(t, 4, u.Orders[1].ID)
+	assert.Equal(t, "", u.Orders[0].Internal)
+	assert.Equal(t, "", u.Orders[1].Internal)
+
+	var u2 pathUser
+	Clone(src, &u2, WithIgnoredPaths("Orders[1].Internal"))
+	assert.Equal(t, "x", u2.Orders[0].Internal)
+	assert.Equal(t, "", u2.Orders[1].Internal)
+
+	var dto pathUserDto
+	Clone(src, &dto, WithPathMappings(map[string]string{
+		"Profile.Nick":   "Nickname",
+		"Orders[*].Cost": "Price",
+	}), WithIgnoredPaths("Orders[*].Internal"))
+	assert.Equal(t, "neo", dto.Profile.Nickname)
+	assert.Equal(t, "matrix", dto.Profile.Password)
+	assert.Equal(t, 9.5, dto.Orders[0].Price)
+	assert.Equal(t, "", dto.Orders[0].Internal)
+	assert.Equal(t, 3, dto.Orders[0].ID)
+}
+
+// resetClonePlans drops the cached plans, so that the next copying
+// builds them again as the first one of a type pair does.
+func resetClonePlans() {
+	clonePlans.Range(func(key, value interface{}) bool {
+		clonePlans.Delete(key)
+		return true
+	})
+}
+
+func BenchmarkClone(b *testing.B) {
+	c := buildDefaultCloner()
+
+	b.Run("struct to struct, cold plan cache", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resetClonePlans()
+			var employee Employee
+			_ = c.Copy(&user1, &employee)
+		}
+	})
+	b.Run("struct to struct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var employee Employee
+			_ = c.Copy(&user1, &employee)
+		}
+	})
+	b.Run("struct to same struct, cold plan cache", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			resetClonePlans()
+			var u User
+			_ = c.Copy(&user1, &u)
+		}
+	})
+	b.Run("struct to same struct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var u User
+			_ = c.Copy(&user1, &u)
+		}
+	})
+	b.Run("struct to struct, parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				var employee Employee
+				_ = c.Copy(&user1, &employee)
+			}
+		})
+	})
+}
+
+func TestClonePlanConcurrently(t *testing.T) {
+	defer initLogger(t)()
+
+	type planSrc struct {
+		A int
+		B string `copy:"name=C"`
+		D []int
+	}
+	type planTgt struct {
+		A int64
+		C string
+		D []int
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var tgt planTgt
+			if err := DefaultCloner.Copy(&planSrc{A: i, B: "b", D: []int{i}}, &tgt); err != nil {
+				t.Errorf("err: %v", err)
+				return
+			}
+			if tgt.A != int64(i) || tgt.C != "b" || tgt.D[0] != i {
+				t.Errorf("wrong clone: %+v", tgt)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+type patchAddress struct {
+	City   string
+	Street string
+}
+
+type patchProfile struct {
+	Nickname string
+	Since    time.Time
+	Address  patchAddress
+	Home     *patchAddress
+	Labels   map[string]interface{}
+}
+
+func TestCloneKeepIfFromIsZero(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("Patch: keep existing values", testPatch_keepExisting)
+	t.Run("Patch: reset to zero", testPatch_resetToZero)
+	t.Run("Patch: off by default", testPatch_offByDefault)
+}
+
+func testPatch_offByDefault(t *testing.T) {
+	target := patchProfile{
+		Nickname: "neo",
+		Home:     &patchAddress{City: "Paris"},
+		Labels:   map[string]interface{}{"z": 9},
+	}
+	source := patchProfile{Address: patchAddress{Street: "Side"}, Labels: map[string]interface{}{"a": 1}}
+
+	if err := CloneE(&source, &target); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, source, target)
+}
+
+func testPatch_keepExisting(t *testing.T) {
+	since := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	home := &patchAddress{City: "Paris", Street: "Rue"}
+	target := patchProfile{
+		Nickname: "neo",
+		Since:    since,
+		Address:  patchAddress{City: "Berlin", Street: "Main"},
+		Home:     home,
+		Labels:   map[string]interface{}{"a": 1, "b": "bb", "nested": map[string]interface{}{"x": 1, "y": 2}},
+	}
+	patch := patchProfile{
+		Address: patchAddress{Street: "Side"},
+		Home:    &patchAddress{City: "Lyon"},
+		Labels:  map[string]interface{}{"a": 0, "c": true, "nested": map[string]interface{}{"y": 3}},
+	}
+
+	if err := DefaultCloner.Copy(&patch, &target, Patch()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	assert.Equal(t, "neo", target.Nickname)
+	assert.Equal(t, since, target.Since)
+	assert.Equal(t, patchAddress{City: "Berlin", Street: "Side"}, target.Address)
+	assert.Equal(t, patchAddress{City: "Lyon", Street: "Rue"}, *target.Home)
+	assert.Equal(t, 1, target.Labels["a"])
+	assert.Equal(t, "bb", target.Labels["b"])
+	assert.Equal(t, true, target.Labels["c"])
+	assert.Equal(t, map[string]interface{}{"x": 1, "y": 3}, target.Labels["nested"])
+
+	// a zero patch changes nothing
+	var empty patchProfile
+	if err := DefaultCloner.Copy(&empty, &target, Patch()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "neo", target.Nickname)
+	assert.Equal(t, since, target.Since)
+	if target.Home == nil {
+		t.Fatal("the nil pointer should not clobber the existing target")
+	}
+}
+
+func testPatch_resetToZero(t *testing.T) {
+	target := patchProfile{
+		Nickname: "neo",
+		Since:    time.Now(),
+		Address:  patchAddress{City: "Berlin"},
+		Home:     &patchAddress{City: "Paris"},
+	}
+	source := patchProfile{Address: patchAddress{Street: "Side"}}
+
+	Clone(&source, &target, WithKeepIfFromIsNilOrZero(false, false))
+	assert.Equal(t, "", target.Nickname)
+	assert.Equal(t, true, target.Since.IsZero())
+	assert.Equal(t, patchAddress{Street: "Side"}, target.Address)
+	if target.Home != nil {
+		t.Fatal("the nil pointer should reset the target")
+	}
+}
+
+type privDocument struct {
+	Title   string
+	content []string
+	meta    map[string]string
+	cursor  *privCursor
+	version int
+	secret  string `copy:"-"`
+}
+
+type privCursor struct {
+	Line int
+	col  int
+}
+
+func TestCloneUnexportedFields(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("unexported fields: ignored by default", testUnexported_ignored)
+	t.Run("unexported fields: cloned deeply", testUnexported_cloned)
+	t.Run("unexported fields: nested private struct", testUnexported_nested)
+}
+
+func newPrivDocument() privDocument {
+	return privDocument{
+		Title:   "doc",
+		content: []string{"hello"},
+		meta:    map[string]string{"author": "tom"},
+		cursor:  &privCursor{Line: 1, col: 2},
+		version: 3,
+		secret:  "s",
+	}
+}
+
+func testUnexported_ignored(t *testing.T) {
+	var snapshot privDocument
+	doc := newPrivDocument()
+	if err := CloneE(&doc, &snapshot); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "doc", snapshot.Title)
+	assert.Equal(t, 0, snapshot.version)
+	assert.Nil(t, snapshot.content)
+}
+
+func testUnexported_cloned(t *testing.T) {
+	for _, from := range []interface{}{newPrivDocument(), ptrPrivDocument()} {
+		var snapshot privDocument
+		if err := CloneE(from, &snapshot, WithUnexportedFields(true)); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assert.Equal(t, "doc", snapshot.Title)
+		assert.Equal(t, 3, snapshot.version)
+		assert.Equal(t, []string{"hello"}, snapshot.content)
+		assert.Equal(t, map[string]string{"author": "tom"}, snapshot.meta)
+		assert.Equal(t, privCursor{Line: 1, col: 2}, *snapshot.cursor)
+		assert.Equal(t, "", snapshot.secret)
+
+		if doc, ok := from.(*privDocument); ok {
+			// the snapshot is independent
+			doc.content[0], doc.meta["author"], doc.cursor.col = "x", "x", 100
+			assert.Equal(t, []string{"hello"}, snapshot.content)
+			assert.Equal(t, "tom", snapshot.meta["author"])
+			assert.Equal(t, 2, snapshot.cursor.col)
+		}
+	}
+}
+
+type privInner struct {
+	items []int
+	tags  map[string]bool
+}
+
+type privOuter struct {
+	st privInner
+}
+
+func testUnexported_nested(t *testing.T) {
+	doc := privOuter{st: privInner{items: []int{1, 2}, tags: map[string]bool{"a": true}}}
+	var snapshot privOuter
+	if err := CloneE(&doc, &snapshot, WithUnexportedFields(true)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, doc, snapshot)
+
+	doc.st.items[0], doc.st.tags["a"] = 100, false
+	assert.Equal(t, []int{1, 2}, snapshot.st.items)
+	assert.Equal(t, true, snapshot.st.tags["a"])
+
+	var inner privInner
+	if err := CloneE(&snapshot.st, &inner, WithUnexportedFields(true)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	snapshot.st.items[1] = 200
+	assert.Equal(t, []int{1, 2}, inner.items)
+}
+
+func ptrPrivDocument() *privDocument {
+	doc := newPrivDocument()
+	return &doc
+}
+
+type hydBase struct {
+	ID   int
+	Kind string
+}
+
+type HydMeta struct {
+	Owner string
+}
+
+type hydServer struct {
+	Host string
+	Port int
+}
+
+type hydConfig struct {
+	hydBase
+	*HydMeta
+	Name    string
+	Primary *hydServer
+	Servers []hydServer
+	Backups []*hydServer
+	Named   map[string]hydServer
+	Labels  map[string]string
+	Ratio   float32
+}
+
+func TestCloneMapToStruct(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("map to struct: nested", testMapToStruct_nested)
+	t.Run("map to struct: patch", testMapToStruct_patch)
+}
+
+func testMapToStruct_nested(t *testing.T) {
+	// such as a tree decoded from json or yaml
+	m := map[string]interface{}{
+		"ID":      float64(7),
+		"Kind":    "svc",
+		"Owner":   "tom",
+		"name":    "demo",
+		"primary": map[string]interface{}{"Host": "a", "Port": float64(80)},
+		"Servers": []interface{}{
+			map[string]interface{}{"Host": "b", "Port": 81},
+			map[string]interface{}{"Host": "c", "Port": 82},
+		},
+		"Backups": []interface{}{map[string]interface{}{"Host": "d"}},
+		"Named":   map[string]interface{}{"x": map[string]interface{}{"Host": "e", "Port": 83}},
+		"Labels":  map[string]interface{}{"env": "dev"},
+		"Ratio":   0.5,
+		"Unknown": 1,
+	}
+
+	var cfg hydConfig
+	if err := CloneE(m, &cfg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	assert.Equal(t, hydBase{ID: 7, Kind: "svc"}, cfg.hydBase)
+	if cfg.HydMeta == nil {
+		t.Fatal("the embedded pointer should be allocated")
+	}
+	assert.Equal(t, "tom", cfg.Owner)
+	assert.Equal(t, "demo", cfg.Name)
+	assert.Equal(t, hydServer{Host: "a", Port: 80}, *cfg.Primary)
+	assert.Equal(t, []hydServer{{Host: "b", Port: 81}, {Host: "c", Port: 82}}, cfg.Servers)
+	assert.Equal(t, 1, len(cfg.Backups))
+	assert.Equal(t, hydServer{Host: "d"}, *cfg.Backups[0])
+	assert.Equal(t, map[string]hydServer{"x": {Host: "e", Port: 83}}, cfg.Named)
+	assert.Equal(t, map[string]string{"env": "dev"}, cfg.Labels)
+	assert.Equal(t, float32(0.5), cfg.Ratio)
+}
+
+func testMapToStruct_patch(t *testing.T) {
+	primary := &hydServer{Host: "a", Port: 80}
+	cfg := hydConfig{Name: "demo", Primary: primary}
+	m := map[string]interface{}{
+		"Primary": map[string]interface{}{"Port": 8080},
+	}
+
+	if err := CloneE(m, &cfg, Patch()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "demo", cfg.Name)
+	assert.Equal(t, true, cfg.Primary == primary)
+	assert.Equal(t, hydServer{Host: "a", Port: 8080}, *cfg.Primary)
+
+	var errs CloneErrors
+	err := CloneE(map[string]interface{}{"Servers": []interface{}{map[string]interface{}{"Port": "x"}}}, &cfg)
+	if !errors.As(err, &errs) {
+		t.Fatalf("expecting CloneErrors, but got %v", err)
+	}
+	assert.Equal(t, "Servers[0].Port", errs[0].Path)
+}
+
+type mtSource struct {
+	First, Last string
+	Age         int
+	Pending     []mtSource
+	ageErr      error
+}
+
+func (s mtSource) GetFullName() string { return s.First + " " + s.Last }
+func (s *mtSource) GetYears() (int, error) {
+	if s.ageErr != nil {
+		return 0, s.ageErr
+	}
+	return s.Age, nil
+}
+func (s mtSource) Score() int { return len(s.First) }
+
+type mtTarget struct {
+	FullName string
+	Years    int64
+	Score    int
+	Pending  []mtTarget
+}
+
+// mtBehavior exposes its states by the setters only
+type mtBehavior struct {
+	first string
+	age   int
+}
+
+func (b *mtBehavior) SetFirst(s string) { b.first = s }
+func (b *mtBehavior) SetAge(age int) error {
+	if age < 0 {
+		return errors.New("negative age %d", age)
+	}
+	b.age = age
+	return nil
+}
+
+func TestCloneMethods(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("getters", testCloneMethods_getters)
+	t.Run("getter error aborts", testCloneMethods_getterError)
+	t.Run("setters", testCloneMethods_setters)
+}
+
+func testCloneMethods_getters(t *testing.T) {
+	var to mtTarget
+	if err := CloneE(mtSource{First: "tom", Last: "li", Age: 18}, &to); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "tom li", to.FullName)
+	assert.Equal(t, int64(18), to.Years)
+	assert.Equal(t, 3, to.Score)
+}
+
+func testCloneMethods_getterError(t *testing.T) {
+	from := mtSource{First: "tom", Pending: []mtSource{
+		{First: "a", Age: 1, ageErr: errors.New("age unknown")},
+		{First: "b", Age: 2},
+	}}
+	var to mtTarget
+	err := CloneE(from, &to)
+
+	var me *MethodError
+	if !errors.As(err, &me) {
+		t.Fatalf("expecting a MethodError but got %v", err)
+	}
+	assert.Equal(t, "GetYears", me.Method)
+	var errs CloneErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expecting CloneErrors but got %T", err)
+	}
+	assert.Equal(t, "Pending[0].GetYears", errs[0].Path)
+
+	// the cloning is aborted, the rest fields and getters are not copied
+	assert.Equal(t, 0, len(to.Pending))
+	assert.Equal(t, "", to.FullName)
+}
+
+func testCloneMethods_setters(t *testing.T) {
+	var b mtBehavior
+	if err := CloneE(mtSource{First: "tom", Age: 18}, &b); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "tom", b.first)
+	assert.Equal(t, 18, b.age)
+
+	err := CloneE(mtSource{First: "jerry", Age: -1}, &b)
+	var me *MethodError
+	if !errors.As(err, &me) {
+		t.Fatalf("expecting a MethodError but got %v", err)
+	}
+	assert.Equal(t, "SetAge", me.Method)
+	assert.Equal(t, `field "Age": method SetAge: negative age -1`, err.Error())
+	assert.Equal(t, 18, b.age)
+}
+
+func TestCloneOpts(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("Copy: per-call options", testCloneOpts_perCall)
+	t.Run("Copy: WithBackend", testCloneOpts_backend)
+}
+
+type optProfile struct {
+	Name  string
+	Email string
+	Age   int
+	Tags  []string
+}
+
+func testCloneOpts_perCall(t *testing.T) {
+	to := optProfile{Name: "old", Email: "old@x.com", Age: 40, Tags: []string{"a"}}
+	from := optProfile{Name: "tom", Email: ""}
+	if err := DefaultCloner.Copy(from, &to, IgnoreFields("Name"), KeepZero()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, optProfile{Name: "old", Email: "old@x.com", Age: 40}, to)
+
+	// DefaultCloner itself is not changed by the options
+	to = optProfile{Name: "old", Email: "old@x.com", Age: 40}
+	if err := DefaultCloner.Copy(from, &to); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, optProfile{Name: "tom"}, to)
+
+	// the With... helpers are the same options
+	to = optProfile{}
+	if err := DefaultCloner.Copy(optProfile{Name: "tom", Age: 3}, &to, WithIgnoredFieldNames("Age", "Email"), WithNameMappings(map[string]string{"Name": "Email"})); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, optProfile{Email: "tom"}, to)
+}
+
+func testCloneOpts_backend(t *testing.T) {
+	type jsonProfile struct {
+		Name string `json:"n"`
+	}
+	var to jsonProfile
+	if err := DefaultCloner.Copy(map[string]interface{}{"n": "tom"}, &to, WithBackend(JSONCopier)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "tom", to.Name)
+
+	// the backends cannot apply the rest options, they're never ignored
+	// silently
+	type secretProfile struct {
+		Name     string
+		Password string
+	}
+	from := secretProfile{Name: "tom", Password: "secret"}
+	for _, backend := range []Cloner{JSONCopier, LazyGobCopier, GeneratedCopier} {
+		var dto secretProfile
+		err := CloneE(from, &dto, WithBackend(backend), IgnoreFields("Password"))
+		var oe *UnsupportedOptionError
+		if !errors.As(err, &oe) {
+			t.Fatalf("%T: expecting an UnsupportedOptionError but got %v", backend, err)
+		}
+		assert.Equal(t, "", dto.Password)
+		assert.Error(t, backend.Copy(from, &dto, IgnoreFields("Password")))
+		assert.Equal(t, "", dto.Password)
+	}
+}
+
+func TestClonePolicy(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("deep by default", testClonePolicy_deep)
+	t.Run("shallow", testClonePolicy_shallow)
+	t.Run("mixed", testClonePolicy_mixed)
+}
+
+type policyInner struct {
+	Tags []string
+}
+
+type policyDoc struct {
+	Count  *int
+	Attrs  map[string][]int
+	Lines  []string
+	Inner  *policyInner
+	OnSave func() string
+	Events chan int
+}
+
+func newPolicyDoc() policyDoc {
+	n := 1
+	return policyDoc{
+		Count:  &n,
+		Attrs:  map[string][]int{"a": {1, 2}},
+		Lines:  []string{"x", "y"},
+		Inner:  &policyInner{Tags: []string{"t"}},
+		OnSave: func() string { return "saved" },
+		Events: make(chan int, 1),
+	}
+}
+
+func testClonePolicy_deep(t *testing.T) {
+	from := newPolicyDoc()
+	var to policyDoc
+	if err := CloneE(from, &to); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	*to.Count = 9
+	to.Attrs["a"][0] = 9
+	to.Attrs["b"] = nil
+	to.Lines[0] = "changed"
+	to.Inner.Tags[0] = "changed"
+	assert.Equal(t, 1, *from.Count)
+	assert.Equal(t, map[string][]int{"a": {1, 2}}, from.Attrs)
+	assert.Equal(t, []string{"x", "y"}, from.Lines)
+	assert.Equal(t, []string{"t"}, from.Inner.Tags)
+
+	// funcs and chans are shared
+	assert.Equal(t, "saved", to.OnSave())
+	assert.Equal(t, from.Events, to.Events)
+
+	// map to map
+	m := map[string]*policyInner{"k": {Tags: []string{"t"}}}
+	tm := map[string]*policyInner{}
+	if err := CloneE(m, &tm); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	tm["k"].Tags[0] = "changed"
+	assert.Equal(t, "t", m["k"].Tags[0])
+}
+
+func testClonePolicy_shallow(t *testing.T) {
+	from := newPolicyDoc()
+	var to policyDoc
+	if err := CloneE(from, &to, WithCopyPolicy(ShallowCopyPolicy)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if to.Count != from.Count || to.Inner != from.Inner {
+		t.Fatal("expecting the pointers are shared")
+	}
+	to.Lines[0] = "shared"
+	to.Attrs["a"][0] = 7
+	assert.Equal(t, "shared", from.Lines[0])
+	assert.Equal(t, 7, from.Attrs["a"][0])
+
+	// the funcs and chans are not copied without ShareFuncs and ShareChans
+	var to2 policyDoc
+	if err := CloneE(from, &to2, WithCopyPolicy(0)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, true, to2.OnSave == nil)
+	assert.Equal(t, true, to2.Events == nil)
+	assert.Equal(t, true, from.Count == to2.Count)
+}
+
+func testClonePolicy_mixed(t *testing.T) {
+	from := newPolicyDoc()
+	var to policyDoc
+	if err := CloneE(from, &to, WithCopyPolicy(DeepPointers|ShareFuncs)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if to.Count == from.Count || to.Inner == from.Inner {
+		t.Fatal("expecting the pointees are cloned")
+	}
+	assert.Equal(t, 1, *to.Count)
+	to.Lines[1] = "shared"
+	assert.Equal(t, "shared", from.Lines[1])
+	// the slice inside a cloned pointee is shared too
+	to.Inner.Tags[0] = "shared"
+	assert.Equal(t, "shared", from.Inner.Tags[0])
+	assert.Equal(t, "saved", to.OnSave())
+	assert.Equal(t, true, to.Events == nil)
+}
@@ -0,0 +1,163 @@
+package ref
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+var (
+	// JSONCopier is a Cloner by a round-trip of encoding/json, it
+	// respects the json tags and the json.Marshaler/json.Unmarshaler
+	// implementations. Only the exported fields are copied, and the
+	// complex numbers, funcs and chans are not supported.
+	//
+	// The source is decoded into the target as json.Unmarshal does,
+	// so the fields absent in the source keep their values.
+	JSONCopier = jsonCopier{}
+
+	// PODCopier is a Cloner which copies a POD (plain old data) value
+	// by an assignment, it's the fastest one but it's a shallow copy.
+	// The source and target must be the same type which holds no
+	// pointers at all, that is, it's composed of the bool, numeric
+	// kinds, and the arrays and structs of them. The unexported fields
+	// are copied too.
+	PODCopier = podCopier{}
+
+	// GeneratedCopier is a Cloner which calls the generated
+	// DeepCopyInto method if the source type has one, such as the
+	// code generated by deepcopy-gen:
+	//
+	//	func (in *T) DeepCopyInto(out *T)
+	//
+	// Or else the source is deeply copied by DeepCopy. The target is
+	// overwritten completely in both cases. DefaultCloner is used if
+	// the types of source and target are different.
+	GeneratedCopier = generatedCopier{}
+)
+
+// WithBackend specifies another Cloner to do the copying for Clone,
+// CloneE and MustClone, such as LazyGobCopier, JSONCopier, PODCopier
+// and GeneratedCopier. They cannot apply the rest options, so an
+// *UnsupportedOptionError is returned if any of them is given, rather
+// than ignoring them silently (such as IgnoreFields for redacting).
+func WithBackend(backend Cloner) CloneOpt {
+	return func(c *cloner) {
+		c.backend = backend
+	}
+}
+
+// jsonCopier is a Cloner by using encoding/json
+type jsonCopier struct{}
+
+func (c jsonCopier) Copy(fromVar, toVar interface{}, Opts ...Opt) (err error) {
+	if err = checkBackendOptions(c, Opts); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = json.Marshal(fromVar); err == nil {
+		err = json.Unmarshal(data, toVar)
+	}
+	return
+}
+
+// podCopier is a Cloner by assigning the POD values
+type podCopier struct{}
+
+func (c podCopier) Copy(fromVar, toVar interface{}, Opts ...Opt) (err error) {
+	if err = checkBackendOptions(c, Opts); err != nil {
+		return
+	}
+	from, to := reflect.ValueOf(fromVar), reflect.ValueOf(toVar)
+	if to.Kind() != reflect.Ptr || to.IsNil() {
+		return &UnsettableError{Type: typeOf(Value{to})}
+	}
+	to = to.Elem()
+	if !from.IsValid() {
+		return &UnsupportedKindError{To: to.Type()}
+	}
+	for from.Kind() == reflect.Ptr && from.Type() != to.Type() {
+		if from.IsNil() {
+			return &UnsupportedKindError{From: from.Type(), To: to.Type()}
+		}
+		from = from.Elem()
+	}
+
+	typ := to.Type()
+	if from.Type() != typ || !isPOD(typ) {
+		return &UnsupportedKindError{From: from.Type(), To: typ}
+	}
+	to.Set(from)
+	return
+}
+
+// podTypes caches the results of isPOD
+var podTypes sync.Map
+
+// isPOD tests whether typ holds no pointers
+func isPOD(typ reflect.Type) bool {
+	if v, ok := podTypes.Load(typ); ok {
+		return v.(bool)
+	}
+
+	var pod bool
+	switch k := typ.Kind(); {
+	case k == reflect.Bool, isNumberKind(k):
+		pod = true
+	case k == reflect.Array:
+		pod = isPOD(typ.Elem())
+	case k == reflect.Struct:
+		pod = true
+		for i := 0; i < typ.NumField() && pod; i++ {
+			pod = isPOD(typ.Field(i).Type)
+		}
+	}
+	podTypes.Store(typ, pod)
+	return pod
+}
+
+// generatedCopier is a Cloner by calling the generated DeepCopyInto
+type generatedCopier struct{}
+
+func (c generatedCopier) Copy(fromVar, toVar interface{}, Opts ...Opt) (err error) {
+	from, to := reflect.ValueOf(fromVar), reflect.ValueOf(toVar)
+	if to.Kind() != reflect.Ptr || to.IsNil() {
+		return &UnsettableError{Type: typeOf(Value{to})}
+	}
+	to = to.Elem()
+	if !from.IsValid() {
+		return &UnsupportedKindError{To: to.Type()}
+	}
+	for from.Kind() == reflect.Ptr && from.Type() != to.Type() && !from.IsNil() {
+		from = from.Elem()
+	}
+	if from.Type() != to.Type() {
+		return DefaultCloner.Copy(fromVar, toVar, Opts...)
+	}
+	if err = checkBackendOptions(c, Opts); err != nil {
+		return
+	}
+
+	if m, ok := deepCopyIntoMethod(from); ok {
+		m.Call([]reflect.Value{to.Addr()})
+		return
+	}
+	to.Set(DeepCopyValue(from))
+	return
+}
+
+// deepCopyIntoMethod returns the bound method DeepCopyInto(out *T) of
+// v (which is a T), v is copied if it's not addressable.
+func deepCopyIntoMethod(v reflect.Value) (m reflect.Value, ok bool) {
+	pt := reflect.PtrTo(v.Type())
+	method, ok := pt.MethodByName("DeepCopyInto")
+	if !ok || method.Type.NumIn() != 2 || method.Type.In(1) != pt || method.Type.NumOut() != 0 {
+		return m, false
+	}
+	if !v.CanAddr() {
+		nv := reflect.New(v.Type()).Elem()
+		nv.Set(v)
+		v = nv
+	}
+	return v.Addr().Method(method.Index), true
+}
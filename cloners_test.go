@@ -0,0 +1,222 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"gopkg.in/hedzr/errors.v2"
+	"reflect"
+	"testing"
+)
+
+type podPoint struct {
+	X, Y  int32
+	Scale [3]float64
+	Valid bool
+	seq   uint16
+}
+
+type genBox struct {
+	Name  string
+	Items []string
+}
+
+var genBoxCopies int
+
+func (in *genBox) DeepCopyInto(out *genBox) {
+	genBoxCopies++
+	*out = *in
+	out.Items = append([]string(nil), in.Items...)
+}
+
+// conformanceCase is a fixture of the Cloner conformance suite
+type conformanceCase struct {
+	name     string
+	from     interface{}                                              // a pointer to the source
+	exported interface{}                                              // the expected target if only the exported fields are copied
+	full     interface{}                                              // the expected target if all fields are copied, the default is exported
+	skip     map[string]bool                                          // the backends which reject this fixture with an error
+	newTo    func() interface{}                                       // a pointer to the zero target
+	check    func(t *testing.T, b conformanceBackend, to interface{}) // the extra checking, optional
+}
+
+// conformanceBackend is a Cloner under the conformance suite
+type conformanceBackend struct {
+	name       string
+	cloner     Cloner
+	unexported bool // the unexported fields are copied
+	sharing    bool // the slices are shared rather than copied
+}
+
+func conformanceBackends() []conformanceBackend {
+	shallow := buildDefaultCloner()
+	shallow.policy = ShallowCopyPolicy
+	return []conformanceBackend{
+		{"reflect", buildDefaultCloner(), false, false},
+		{"reflect-shallow", shallow, false, true},
+		{"gob", LazyGobCopier, false, false},
+		{"json", JSONCopier, false, false},
+		{"pod", PODCopier, true, false},
+		{"generated", GeneratedCopier, true, false},
+	}
+}
+
+func conformanceCases() []conformanceCase {
+	utcNow := now.Round(0).UTC()
+	u := U{Name: uFrom.Name, Birthday: &utcNow, Nickname: uFrom.Nickname}
+	pt := podPoint{X: 3, Y: -4, Scale: [3]float64{1, 2.5, 3}, Valid: true, seq: 9}
+	box := genBox{Name: "box", Items: []string{"a", "b"}}
+	nonPOD := map[string]bool{"pod": true}
+
+	return []conformanceCase{
+		{
+			name: "user1", from: &user1, exported: user1,
+			skip:  map[string]bool{"pod": true, "json": true}, // complex numbers
+			newTo: func() interface{} { return new(User) },
+		},
+		{
+			// U.Clone() is used by the reflect backend
+			name: "uFrom", from: &u, exported: u, skip: nonPOD,
+			newTo: func() interface{} { return new(U) },
+		},
+		{
+			name: "wilson", from: &wilson, exported: Cat{Age: wilson.Age}, full: wilson, skip: nonPOD,
+			newTo: func() interface{} { return new(Cat) },
+		},
+		{
+			name: "pod", from: &pt, exported: podPoint{X: 3, Y: -4, Scale: pt.Scale, Valid: true}, full: pt,
+			newTo: func() interface{} { return new(podPoint) },
+		},
+		{
+			name: "generated", from: &box, exported: box, skip: nonPOD,
+			newTo: func() interface{} { return new(genBox) },
+			check: func(t *testing.T, b conformanceBackend, to interface{}) {
+				if b.sharing {
+					return
+				}
+				to.(*genBox).Items[0] = "changed"
+				assert.Equal(t, "a", box.Items[0])
+			},
+		},
+	}
+}
+
+func TestClonerConformance(t *testing.T) {
+	defer initLogger(t)()
+
+	for _, b := range conformanceBackends() {
+		for _, c := range conformanceCases() {
+			b, c := b, c
+			t.Run(b.name+"/"+c.name, func(t *testing.T) {
+				to := c.newTo()
+				err := b.cloner.Copy(c.from, to)
+				if c.skip[b.name] {
+					if err == nil {
+						t.Fatalf("expecting an error on the unsupported fixture")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("err: %v", err)
+				}
+
+				expect := c.exported
+				if b.unexported && c.full != nil {
+					expect = c.full
+				}
+				actual := reflect.ValueOf(to).Elem().Interface()
+				if !reflect.DeepEqual(expect, actual) {
+					t.Fatalf("expecting %+v but got %+v", expect, actual)
+				}
+				if c.check != nil {
+					c.check(t, b, to)
+				}
+			})
+		}
+	}
+}
+
+// TestClonerConformanceNil tests the nil arguments never panic, and
+// the typed errors are returned by PODCopier and GeneratedCopier.
+func TestClonerConformanceNil(t *testing.T) {
+	defer initLogger(t)()
+
+	for _, b := range conformanceBackends() {
+		b := b
+		t.Run(b.name, func(t *testing.T) {
+			pt := podPoint{X: 1}
+			var to podPoint
+			var ue *UnsettableError
+			var uk *UnsupportedKindError
+			typed := b.name == "pod" || b.name == "generated"
+
+			err := b.cloner.Copy(&pt, nil)
+			if typed && !errors.As(err, &ue) {
+				t.Fatalf("nil target: expecting an UnsettableError but got %v", err)
+			}
+			err = b.cloner.Copy(&pt, (*podPoint)(nil))
+			if typed && !errors.As(err, &ue) {
+				t.Fatalf("nil pointer target: expecting an UnsettableError but got %v", err)
+			}
+			err = b.cloner.Copy(nil, &to)
+			if typed {
+				if !errors.As(err, &uk) {
+					t.Fatalf("nil source: expecting an UnsupportedKindError but got %v", err)
+				}
+				assert.Equal(t, nil, uk.From)
+			}
+		})
+	}
+}
+
+func TestClonerBackends(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("WithBackend", testClonerBackends_withBackend)
+	t.Run("generated: fast path", testClonerBackends_generated)
+	t.Run("pod: rejects", testClonerBackends_podRejects)
+}
+
+func testClonerBackends_withBackend(t *testing.T) {
+	var to podPoint
+	from := podPoint{X: 1, seq: 2}
+	if err := CloneE(from, &to, WithBackend(PODCopier)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, from, to)
+
+	var ue *UnsupportedKindError
+	err := CloneE(user1, new(User), WithBackend(PODCopier))
+	if !errors.As(err, &ue) {
+		t.Fatalf("expecting an UnsupportedKindError but got %v", err)
+	}
+}
+
+func testClonerBackends_generated(t *testing.T) {
+	genBoxCopies = 0
+	var to genBox
+	if err := GeneratedCopier.Copy(genBox{Name: "x"}, &to); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, genBoxCopies)
+	assert.Equal(t, "x", to.Name)
+
+	// DeepCopy takes the fast path for the nested values too
+	type holder struct{ Boxes []genBox }
+	h := DeepCopy(holder{Boxes: []genBox{{Name: "a"}, {Name: "b"}}}).(holder)
+	assert.Equal(t, 3, genBoxCopies)
+	assert.Equal(t, "b", h.Boxes[1].Name)
+
+	// the different types are copied by DefaultCloner
+	var dto struct{ Name string }
+	if err := GeneratedCopier.Copy(&genBox{Name: "y"}, &dto); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "y", dto.Name)
+}
+
+func testClonerBackends_podRejects(t *testing.T) {
+	var to podPoint
+	assert.Error(t, PODCopier.Copy(&podPoint{}, to))
+	assert.Error(t, PODCopier.Copy(&genBox{}, &to))
+	assert.Equal(t, false, isPOD(reflect.TypeOf(genBox{})))
+	assert.Equal(t, true, isPOD(reflect.TypeOf(podPoint{})))
+}
@@ -0,0 +1,319 @@
+package ref
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/hedzr/errors.v2"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrOverflow means a numeric value overflows the target type, such
+	// as converting 300 to int8. It's the cause of a ConversionError.
+	ErrOverflow = errors.New("value overflows the target type")
+	// ErrLossy means a conversion loses the precision, such as
+	// converting 1.5 to int. It's the cause of a ConversionError.
+	ErrLossy = errors.New("lossy conversion")
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// convertValue converts v to the type t, it extends reflect.Convert
+// with a conversion matrix between the numeric, string and bool kinds:
+//
+//   - int <-> uint <-> float, with the overflow and lossy detections
+//     (see ErrOverflow and ErrLossy);
+//   - string -> bool, int, uint, float and time.Duration by parsing,
+//     the ints accept the prefixes 0x, 0o and 0b;
+//   - bool, int, uint, float, complex and time.Duration -> string by
+//     formatting, the ints are formatted as decimal rather than the
+//     runes of reflect.Convert.
+//
+// The standard interfaces take precedence over the matrix, so that
+// the rich types (such as net.IP, big.Int and the enum types) can be
+// populated from the strings:
+//
+//   - string -> encoding.TextUnmarshaler, or json.Unmarshaler as the
+//     fallback;
+//   - encoding.TextMarshaler, or fmt.Stringer -> string.
+//
+// A failure is returned as a *ConversionError.
+func convertValue(v reflect.Value, t reflect.Type) (out reflect.Value, err error) {
+	ft := v.Type()
+	if ft.AssignableTo(t) {
+		if ft == t {
+			return v, nil
+		}
+		return v.Convert(t), nil
+	}
+
+	fk, tk := ft.Kind(), t.Kind()
+	var cause error
+	switch {
+	case fk == reflect.String && canUnmarshalText(t):
+		if out, cause = unmarshalText(v.String(), t); cause == nil {
+			return
+		}
+	case tk == reflect.String && canMarshalText(ft):
+		var s string
+		if s, cause = marshalText(v); cause == nil {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+	case fk == reflect.String && tk != reflect.String:
+		if out, cause = parseString(v.String(), t); cause == nil && out.IsValid() {
+			return
+		}
+	case tk == reflect.String && fk != reflect.String:
+		if s, ok := formatScalar(v); ok {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+	case isNumberKind(fk) && isNumberKind(tk):
+		if out, cause = convertNumber(v, t); cause == nil {
+			return
+		}
+	}
+
+	if cause == nil && ft.ConvertibleTo(t) {
+		return v.Convert(t), nil
+	}
+	return reflect.Value{}, &ConversionError{From: ft, To: t, Err: cause}
+}
+
+// isTextConvertible tests whether the values of from can be converted
+// to the type to through the text interfaces, see convertValue.
+func isTextConvertible(from, to reflect.Type) bool {
+	return (from.Kind() == reflect.String && canUnmarshalText(to)) ||
+		(to.Kind() == reflect.String && canMarshalText(from))
+}
+
+// canUnmarshalText tests whether *t (or t if it's a pointer) implements
+// encoding.TextUnmarshaler or json.Unmarshaler
+func canUnmarshalText(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	if t.Kind() == reflect.Ptr {
+		pt = t
+	}
+	return pt.Implements(textUnmarshalerType) || pt.Implements(jsonUnmarshalerType)
+}
+
+// canMarshalText tests whether t or *t implements encoding.TextMarshaler
+// or fmt.Stringer
+func canMarshalText(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return t.Implements(textMarshalerType) || pt.Implements(textMarshalerType) ||
+		t.Implements(stringerType) || pt.Implements(stringerType)
+}
+
+// unmarshalText makes a value of type t from s by UnmarshalText, or by
+// UnmarshalJSON as the fallback. s is passed to UnmarshalJSON as is if
+// it's a valid JSON text, or as a quoted JSON string.
+func unmarshalText(s string, t reflect.Type) (out reflect.Value, err error) {
+	elem := t
+	if t.Kind() == reflect.Ptr {
+		elem = t.Elem()
+	}
+	ptr := reflect.New(elem)
+	if u, ok := ptr.Interface().(encoding.TextUnmarshaler); ok {
+		err = u.UnmarshalText([]byte(s))
+	} else if u, ok := ptr.Interface().(json.Unmarshaler); ok {
+		data := []byte(s)
+		if !json.Valid(data) {
+			data, _ = json.Marshal(s)
+		}
+		err = u.UnmarshalJSON(data)
+	}
+	if err != nil {
+		return
+	}
+	if t.Kind() == reflect.Ptr {
+		return ptr, nil
+	}
+	return ptr.Elem(), nil
+}
+
+// marshalText formats v by MarshalText, or by String as the fallback.
+// The pointer methods are used if v is addressable or copyable.
+func marshalText(v reflect.Value) (s string, err error) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return
+	}
+	if !v.Type().Implements(textMarshalerType) && !v.Type().Implements(stringerType) {
+		if !v.CanAddr() {
+			nv := reflect.New(v.Type()).Elem()
+			nv.Set(v)
+			v = nv
+		}
+		v = v.Addr()
+	} else if v.Type().Implements(stringerType) && !v.Type().Implements(textMarshalerType) &&
+		reflect.PtrTo(v.Type()).Implements(textMarshalerType) && v.CanAddr() {
+		v = v.Addr() // prefer MarshalText on *T
+	}
+
+	switch x := v.Interface().(type) {
+	case encoding.TextMarshaler:
+		var data []byte
+		if data, err = x.MarshalText(); err == nil {
+			s = string(data)
+		}
+	case fmt.Stringer:
+		s = x.String()
+	}
+	return
+}
+
+// isNumberKind tests the integer, float and complex kinds
+func isNumberKind(k reflect.Kind) bool { return isNumericKind(k) || isKindComplex(k) }
+
+// parseString parses s to the bool, numeric and time.Duration types.
+// It returns an invalid out if t is not supported.
+func parseString(s string, t reflect.Type) (out reflect.Value, err error) {
+	out = reflect.New(t).Elem()
+	switch k := t.Kind(); {
+	case t == durationType:
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err == nil {
+			out.SetInt(int64(d))
+		}
+	case k == reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			out.SetBool(b)
+		}
+	case isKindInt(k):
+		var i int64
+		if i, err = strconv.ParseInt(s, 0, t.Bits()); err == nil {
+			out.SetInt(i)
+		}
+	case isKindUint(k):
+		var u uint64
+		if u, err = strconv.ParseUint(s, 0, t.Bits()); err == nil {
+			out.SetUint(u)
+		}
+	case isKindFloat(k):
+		var f float64
+		if f, err = strconv.ParseFloat(s, t.Bits()); err == nil {
+			out.SetFloat(f)
+		}
+	default:
+		out = reflect.Value{}
+	}
+	if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+		err = ErrOverflow
+	}
+	return
+}
+
+// formatScalar formats the bool, numeric and time.Duration values
+func formatScalar(v reflect.Value) (s string, ok bool) {
+	switch k := v.Kind(); {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String(), true
+	case k == reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case isKindInt(k):
+		return strconv.FormatInt(v.Int(), 10), true
+	case isKindUint(k):
+		return strconv.FormatUint(v.Uint(), 10), true
+	case isKindFloat(k):
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+	case isKindComplex(k):
+		return fmt.Sprint(v.Complex()), true
+	}
+	return
+}
+
+// convertNumber converts between the numeric kinds, the narrowing and
+// lossy conversions are reported.
+func convertNumber(v reflect.Value, t reflect.Type) (out reflect.Value, err error) {
+	out = reflect.New(t).Elem()
+	fk, tk := v.Kind(), t.Kind()
+	switch {
+	case isKindInt(fk):
+		i := v.Int()
+		switch {
+		case isKindInt(tk):
+			if out.OverflowInt(i) {
+				return out, ErrOverflow
+			}
+			out.SetInt(i)
+		case isKindUint(tk):
+			if i < 0 || out.OverflowUint(uint64(i)) {
+				return out, ErrOverflow
+			}
+			out.SetUint(uint64(i))
+		case isKindFloat(tk):
+			out.SetFloat(float64(i))
+		default:
+			out.SetComplex(complex(float64(i), 0))
+		}
+	case isKindUint(fk):
+		u := v.Uint()
+		switch {
+		case isKindInt(tk):
+			if u > math.MaxInt64 || out.OverflowInt(int64(u)) {
+				return out, ErrOverflow
+			}
+			out.SetInt(int64(u))
+		case isKindUint(tk):
+			if out.OverflowUint(u) {
+				return out, ErrOverflow
+			}
+			out.SetUint(u)
+		case isKindFloat(tk):
+			out.SetFloat(float64(u))
+		default:
+			out.SetComplex(complex(float64(u), 0))
+		}
+	case isKindFloat(fk):
+		f := v.Float()
+		switch {
+		case isKindInt(tk):
+			if f != math.Trunc(f) {
+				return out, ErrLossy
+			}
+			if f < math.MinInt64 || f >= math.MaxInt64 || out.OverflowInt(int64(f)) {
+				return out, ErrOverflow
+			}
+			out.SetInt(int64(f))
+		case isKindUint(tk):
+			if f != math.Trunc(f) {
+				return out, ErrLossy
+			}
+			if f < 0 || f >= math.MaxUint64 || out.OverflowUint(uint64(f)) {
+				return out, ErrOverflow
+			}
+			out.SetUint(uint64(f))
+		case isKindFloat(tk):
+			if !math.IsInf(f, 0) && !math.IsNaN(f) && out.OverflowFloat(f) {
+				return out, ErrOverflow
+			}
+			out.SetFloat(f)
+		default:
+			out.SetComplex(complex(f, 0))
+		}
+	default: // complex
+		c := v.Complex()
+		switch {
+		case isKindComplex(tk):
+			if out.OverflowComplex(c) {
+				return out, ErrOverflow
+			}
+			out.SetComplex(c)
+		case imag(c) != 0:
+			return out, ErrLossy
+		default:
+			return convertNumber(reflect.ValueOf(real(c)), t)
+		}
+	}
+	return
+}
@@ -0,0 +1,306 @@
+package ref
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hedzr/assert"
+	"gopkg.in/hedzr/errors.v2"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvert(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("TryConvert: matrix", testConvert_matrix)
+	t.Run("TryConvert: overflow and lossy", testConvert_overflow)
+	t.Run("Clone: loosely typed fields", testConvert_clone)
+	t.Run("Merger: loosely typed fields", testConvert_merger)
+	t.Run("SetField: string to int", testConvert_setField)
+	t.Run("TryConvert: text interfaces", testConvert_text)
+	t.Run("Clone: text interfaces", testConvert_cloneText)
+	t.Run("Merger: text interfaces", testConvert_mergerText)
+}
+
+func testConvert_matrix(t *testing.T) {
+	for _, c := range []struct {
+		from, expect interface{}
+	}{
+		{"12", 12},
+		{"0x1f", int8(31)},
+		{"42", uint16(42)},
+		{"1.5", 1.5},
+		{"true", true},
+		{"1m30s", 90 * time.Second},
+		{12, "12"},
+		{rune(65), "65"},
+		{uint8(7), "7"},
+		{1.25, "1.25"},
+		{float32(0.1), "0.1"},
+		{false, "false"},
+		{2 * time.Hour, "2h0m0s"},
+		{3, 3.0},
+		{2.0, int64(2)},
+		{int64(-1), int8(-1)},
+		{uint64(255), int16(255)},
+		{5, complex(5, 0)},
+		{complex(6, 0), 6},
+	} {
+		out, err := TryConvert(reflect.ValueOf(c.from), reflect.TypeOf(c.expect))
+		if err != nil {
+			t.Fatalf("%v (%T) -> %T: %v", c.from, c.from, c.expect, err)
+		}
+		assert.Equal(t, c.expect, out.Interface())
+	}
+}
+
+func testConvert_overflow(t *testing.T) {
+	for _, c := range []struct {
+		from interface{}
+		to   reflect.Type
+		err  error
+	}{
+		{300, reflect.TypeOf(int8(0)), ErrOverflow},
+		{-1, reflect.TypeOf(uint(0)), ErrOverflow},
+		{uint64(1 << 63), reflect.TypeOf(int64(0)), ErrOverflow},
+		{1e40, reflect.TypeOf(float32(0)), ErrOverflow},
+		{1e20, reflect.TypeOf(int64(0)), ErrOverflow},
+		{"300", reflect.TypeOf(uint8(0)), ErrOverflow},
+		{1.5, reflect.TypeOf(0), ErrLossy},
+		{complex(1, 2), reflect.TypeOf(0.0), ErrLossy},
+	} {
+		_, err := TryConvert(reflect.ValueOf(c.from), c.to)
+		var ce *ConversionError
+		if !errors.As(err, &ce) {
+			t.Fatalf("%v -> %v: expecting a ConversionError but got %v", c.from, c.to, err)
+		}
+		assert.Equal(t, true, errors.Is(err, c.err))
+	}
+
+	_, err := TryConvert(reflect.ValueOf("abc"), reflect.TypeOf(0))
+	assert.Error(t, err)
+	_, err = TryConvert(reflect.ValueOf([]int{1}), reflect.TypeOf(""))
+	assert.Error(t, err)
+}
+
+type looseConfig struct {
+	Port    string
+	Timeout string
+	Debug   string
+	Ratio   int
+	Levels  []int
+	Small   int
+}
+
+type strictConfig struct {
+	Port    int
+	Timeout time.Duration
+	Debug   bool
+	Ratio   float32
+	Levels  []string
+	Small   int8
+}
+
+func testConvert_clone(t *testing.T) {
+	var to strictConfig
+	from := looseConfig{Port: "8080", Timeout: "5s", Debug: "true", Ratio: 3, Levels: []int{1, 2}, Small: 300}
+	err := CloneE(from, &to)
+
+	var errs CloneErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expecting CloneErrors but got %v", err)
+	}
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "Small", errs[0].Path)
+	assert.Equal(t, true, errors.Is(err, ErrOverflow))
+
+	assert.Equal(t, strictConfig{Port: 8080, Timeout: 5 * time.Second, Debug: true, Ratio: 3, Levels: []string{"1", "2"}}, to)
+
+	var back looseConfig
+	to.Small = 100
+	if err = CloneE(to, &back); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, looseConfig{Port: "8080", Timeout: "5s", Debug: "true", Ratio: 3, Levels: []int{1, 2}, Small: 100}, back)
+
+	var ss []string
+	if err = CloneE([]int{3, 4}, &ss); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"3", "4"}, ss)
+}
+
+func testConvert_merger(t *testing.T) {
+	var to strictConfig
+	err := NewMerger(map[string]interface{}{
+		"Port":    "8080",
+		"Timeout": "1m",
+		"Debug":   "1",
+		"Ratio":   2,
+	}).MergeTo(&to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, strictConfig{Port: 8080, Timeout: time.Minute, Debug: true, Ratio: 2}, to)
+
+	var i8 int8
+	assert.Error(t, NewMerger(300).MergeTo(&i8))
+}
+
+func testConvert_setField(t *testing.T) {
+	var rc richConfig
+	assert.NoError(t, SetField(&rc, "Addr", "1.2.3.4"))
+	assert.Equal(t, net.ParseIP("1.2.3.4"), rc.Addr)
+	assert.NoError(t, SetField(&rc, "Total", "8"))
+	assert.Equal(t, big.NewInt(8), rc.Total)
+
+	s := testStruct{}
+	assert.NoError(t, SetField(&s, "Yummy", "123"))
+	assert.Equal(t, 123, s.Yummy)
+	assert.NoError(t, SetField(&s, "Dummy", 45))
+	assert.Equal(t, "45", s.Dummy)
+	assert.Error(t, SetField(&s, "Yummy", 1.5))
+}
+
+type cvtColor int
+
+const (
+	cvtRed cvtColor = iota + 1
+	cvtGreen
+)
+
+func (c cvtColor) MarshalText() ([]byte, error) {
+	switch c {
+	case cvtRed:
+		return []byte("red"), nil
+	case cvtGreen:
+		return []byte("green"), nil
+	}
+	return nil, fmt.Errorf("unknown color %d", int(c))
+}
+
+func (c *cvtColor) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "red":
+		*c = cvtRed
+	case "green":
+		*c = cvtGreen
+	default:
+		return fmt.Errorf("unknown color %q", text)
+	}
+	return nil
+}
+
+// cvtLevel has UnmarshalJSON and String only
+type cvtLevel struct{ n int }
+
+func (l cvtLevel) String() string { return fmt.Sprintf("L%d", l.n) }
+
+func (l *cvtLevel) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err == nil {
+		_, err = fmt.Sscanf(s, "L%d", &l.n)
+	}
+	return
+}
+
+func testConvert_text(t *testing.T) {
+	for _, c := range []struct {
+		from, expect interface{}
+	}{
+		{"Green", cvtGreen},
+		{cvtRed, "red"},
+		{"10.0.0.1", net.ParseIP("10.0.0.1")},
+		{net.ParseIP("10.0.0.1"), "10.0.0.1"},
+		{"12345678901234567890", *big.NewInt(0).SetUint64(12345678901234567890)},
+		{*big.NewInt(42), "42"},
+		{big.NewInt(43), "43"},
+		{"L3", cvtLevel{3}},
+		{cvtLevel{4}, "L4"},
+		{time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), "2021-01-02T03:04:05Z"},
+	} {
+		out, err := TryConvert(reflect.ValueOf(c.from), reflect.TypeOf(c.expect))
+		if err != nil {
+			t.Fatalf("%v (%T) -> %T: %v", c.from, c.from, c.expect, err)
+		}
+		assert.Equal(t, c.expect, out.Interface())
+	}
+
+	out, err := TryConvert(reflect.ValueOf("7"), reflect.TypeOf((*big.Int)(nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(7), out.Interface())
+
+	_, err = TryConvert(reflect.ValueOf("blue"), reflect.TypeOf(cvtRed))
+	var ce *ConversionError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expecting a ConversionError but got %v", err)
+	}
+	_, err = TryConvert(reflect.ValueOf(cvtColor(9)), reflect.TypeOf(""))
+	assert.Error(t, err)
+}
+
+type textConfig struct {
+	Addr  string
+	Color string
+	Total string
+	Level string
+}
+
+type richConfig struct {
+	Addr  net.IP
+	Color cvtColor
+	Total *big.Int
+	Level cvtLevel
+}
+
+func testConvert_cloneText(t *testing.T) {
+	var to richConfig
+	from := textConfig{Addr: "192.168.0.1", Color: "red", Total: "99", Level: "L2"}
+	if err := CloneE(from, &to); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, richConfig{Addr: net.ParseIP("192.168.0.1"), Color: cvtRed, Total: big.NewInt(99), Level: cvtLevel{2}}, to)
+
+	var back textConfig
+	if err := CloneE(to, &back); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, from, back)
+
+	err := CloneE(textConfig{Color: "blue"}, &to)
+	var errs CloneErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expecting CloneErrors but got %v", err)
+	}
+	assert.Equal(t, "Color", errs[0].Path)
+}
+
+func testConvert_mergerText(t *testing.T) {
+	var to richConfig
+	err := NewMerger(map[string]interface{}{
+		"Addr":  "::1",
+		"Color": "green",
+		"Total": "100",
+		"Level": "L5",
+	}).MergeTo(&to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, richConfig{Addr: net.ParseIP("::1"), Color: cvtGreen, Total: big.NewInt(100), Level: cvtLevel{5}}, to)
+
+	var back textConfig
+	if err = NewMerger(to).MergeTo(&back); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, textConfig{Addr: "::1", Color: "green", Total: "100", Level: "L5"}, back)
+
+	var ip net.IP
+	if err = NewMerger("127.0.0.1").MergeTo(&ip); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, net.ParseIP("127.0.0.1"), ip)
+}
@@ -0,0 +1,68 @@
+package ref
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// CopierFunc copies the source value 'from' into the settable target
+// value 'to'. It can be registered for a type by RegisterCopier.
+type CopierFunc func(from, to reflect.Value) error
+
+var copiers = struct {
+	sync.RWMutex
+	m     map[reflect.Type]CopierFunc
+	count int32 // the length of m, for a lock-free checking
+}{m: make(map[reflect.Type]CopierFunc)}
+
+// RegisterCopier registers a custom copier for the given source type.
+//
+// The cloner consults the registered copiers before its generic
+// logic, so you can teach it how to clone the third-party types which
+// you can't add a Clone() method to:
+//
+//	ref.RegisterCopier(reflect.TypeOf(sync.Mutex{}), ref.SkipCopier)
+//	ref.RegisterCopier(reflect.TypeOf((*big.Int)(nil)), func(from, to reflect.Value) error {
+//		to.Set(reflect.ValueOf(new(big.Int).Set(from.Interface().(*big.Int))))
+//		return nil
+//	})
+//
+// Registering a nil copier removes the existing one.
+func RegisterCopier(typ reflect.Type, copier CopierFunc) {
+	copiers.Lock()
+	defer copiers.Unlock()
+	if copier == nil {
+		delete(copiers.m, typ)
+	} else {
+		copiers.m[typ] = copier
+	}
+	atomic.StoreInt32(&copiers.count, int32(len(copiers.m)))
+}
+
+// UnregisterCopier removes the custom copier of the given source type.
+func UnregisterCopier(typ reflect.Type) {
+	RegisterCopier(typ, nil)
+}
+
+// SkipCopier is a CopierFunc which copies nothing, the target keeps
+// its original value. It's useful for the types like sync.Mutex.
+func SkipCopier(from, to reflect.Value) error { return nil }
+
+// ShallowCopier is a CopierFunc which assigns the source to target
+// directly, the pointers, maps and slices will be shared between them.
+// It's useful for the immutable types like *time.Location.
+func ShallowCopier(from, to reflect.Value) error {
+	to.Set(from)
+	return nil
+}
+
+func findCopier(typ reflect.Type) (copier CopierFunc, ok bool) {
+	if typ == nil || atomic.LoadInt32(&copiers.count) == 0 {
+		return
+	}
+	copiers.RLock()
+	defer copiers.RUnlock()
+	copier, ok = copiers.m[typ]
+	return
+}
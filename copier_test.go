@@ -0,0 +1,67 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type maskedString string
+
+type accountWithCopiers struct {
+	Name     string
+	Password maskedString
+	Balance  *big.Int
+	Mu       sync.Mutex
+}
+
+func TestRegisterCopier(t *testing.T) {
+	defer initLogger(t)()
+
+	RegisterCopier(reflect.TypeOf(maskedString("")), func(from, to reflect.Value) error {
+		to.SetString("***")
+		return nil
+	})
+	RegisterCopier(reflect.TypeOf((*big.Int)(nil)), func(from, to reflect.Value) error {
+		if !from.IsNil() {
+			to.Set(reflect.ValueOf(new(big.Int).Set(from.Interface().(*big.Int))))
+		}
+		return nil
+	})
+	RegisterCopier(reflect.TypeOf(sync.Mutex{}), SkipCopier)
+	defer func() {
+		UnregisterCopier(reflect.TypeOf(maskedString("")))
+		UnregisterCopier(reflect.TypeOf((*big.Int)(nil)))
+		UnregisterCopier(reflect.TypeOf(sync.Mutex{}))
+	}()
+
+	src := &accountWithCopiers{
+		Name:     "alice",
+		Password: "secret",
+		Balance:  big.NewInt(1024),
+	}
+	var tgt accountWithCopiers
+	if err := DefaultCloner.Copy(src, &tgt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	assert.Equal(t, "alice", tgt.Name)
+	assert.Equal(t, maskedString("***"), tgt.Password)
+	if tgt.Balance == src.Balance || tgt.Balance.Cmp(src.Balance) != 0 {
+		t.Fatalf("the *big.Int should be cloned by the registered copier, got %v", tgt.Balance)
+	}
+
+	var history []maskedString
+	if err := DefaultCloner.Copy([]maskedString{"old-secret"}, &history); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, []maskedString{"***"}, history)
+
+	UnregisterCopier(reflect.TypeOf(maskedString("")))
+	if err := DefaultCloner.Copy(src, &tgt); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, maskedString("secret"), tgt.Password)
+}
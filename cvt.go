@@ -8,6 +8,11 @@ import (
 )
 
 // TryConvert calls reflect.Convert safely, without panic threw.
+//
+// The numeric, string and bool values are converted each other by a
+// conversion matrix, such as "12" -> int, 1.0 -> int8, 3 -> "3",
+// "1s" -> time.Duration. The overflows and lossy conversions are
+// reported as a *ConversionError, see also ErrOverflow and ErrLossy.
 func TryConvert(v reflect.Value, t reflect.Type) (out reflect.Value, err error) {
 	return tryConvert(v, t)
 }
@@ -15,15 +20,15 @@ func TryConvert(v reflect.Value, t reflect.Type) (out reflect.Value, err error)
 func tryConvert(v reflect.Value, t reflect.Type) (out reflect.Value, err error) {
 	defer func() {
 		if e := recover(); e != nil {
-			if e2, ok := e.(error); ok {
-				err = e2
-			} else {
-				err = errors.New("%v", e)
+			e2, ok := e.(error)
+			if !ok {
+				e2 = errors.New("%v", e)
 			}
+			err = &ConversionError{From: v.Type(), To: t, Err: e2}
 		}
 	}()
 
-	out = v.Convert(t)
+	out, err = convertValue(v, t)
 	return
 }
 
@@ -0,0 +1,152 @@
+package ref
+
+import (
+	"reflect"
+	"time"
+)
+
+// locationType is shared rather than copied, so that the time.Time
+// values can be compared with == still.
+var locationType = reflect.TypeOf((*time.Location)(nil))
+
+// DeepCopy returns a fully independent deep copy of v, the maps,
+// slices, arrays, pointers, interfaces and structs (including their
+// unexported fields) are allocated newly, for example:
+//
+//	snapshot := ref.DeepCopy(state).(*State)
+//
+// The cycles and the shared pointers are kept in the copy. The funcs,
+// chans and unsafe pointers are shared with v. A registered copier
+// (see RegisterCopier) is used for its type, and the generated method
+// DeepCopyInto(out *T) is used for the type T which has it.
+func DeepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return DeepCopyValue(reflect.ValueOf(v)).Interface()
+}
+
+// DeepCopyValue returns a fully independent deep copy of v, see also
+// DeepCopy.
+//
+// If v is an unexported field, it must be addressable.
+func DeepCopyValue(v reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	if !v.CanInterface() && v.CanAddr() {
+		v = accessibleField(v)
+	}
+
+	d := deepCopier{visited: make(map[visit]reflect.Value)}
+	out := reflect.New(v.Type()).Elem()
+	d.copy(v, out)
+	return out
+}
+
+// deepCopier copies a value to a newly allocated value completely, it
+// ignores all of the cloning rules.
+type deepCopier struct {
+	visited map[visit]reflect.Value
+}
+
+// copy copies from to the settable zero value to.
+func (d *deepCopier) copy(from, to reflect.Value) {
+	if copier, ok := findCopier(from.Type()); ok {
+		if err := copier(from, to); err == nil {
+			return
+		}
+	}
+	if from.Kind() == reflect.Struct {
+		if m, ok := deepCopyIntoMethod(from); ok {
+			m.Call([]reflect.Value{to.Addr()})
+			return
+		}
+	}
+
+	switch from.Kind() {
+	case reflect.Interface:
+		if from.IsNil() {
+			return
+		}
+		elem := from.Elem()
+		nv := reflect.New(elem.Type()).Elem()
+		d.copy(elem, nv)
+		to.Set(nv)
+
+	case reflect.Ptr:
+		if from.IsNil() {
+			return
+		}
+		if from.Type() == locationType {
+			to.Set(from)
+			return
+		}
+		key := visit{from.Pointer(), from.Type()}
+		if v, ok := d.visited[key]; ok {
+			to.Set(v)
+			return
+		}
+		nv := reflect.New(from.Type().Elem())
+		d.visited[key] = nv
+		d.copy(from.Elem(), nv.Elem())
+		to.Set(nv)
+
+	case reflect.Map:
+		if from.IsNil() {
+			return
+		}
+		key := visit{from.Pointer(), from.Type()}
+		if v, ok := d.visited[key]; ok {
+			to.Set(v)
+			return
+		}
+		nm := reflect.MakeMapWithSize(from.Type(), from.Len())
+		d.visited[key] = nm
+		kt, vt := from.Type().Key(), from.Type().Elem()
+		for _, k := range from.MapKeys() {
+			nk, nv := reflect.New(kt).Elem(), reflect.New(vt).Elem()
+			d.copy(k, nk)
+			d.copy(from.MapIndex(k), nv)
+			nm.SetMapIndex(nk, nv)
+		}
+		to.Set(nm)
+
+	case reflect.Slice:
+		if from.IsNil() {
+			return
+		}
+		n := from.Len()
+		key := visit{from.Pointer(), from.Type()}
+		if v, ok := d.visited[key]; ok && v.Len() == n {
+			to.Set(v)
+			return
+		}
+		ns := reflect.MakeSlice(from.Type(), n, n)
+		d.visited[key] = ns
+		for i := 0; i < n; i++ {
+			d.copy(from.Index(i), ns.Index(i))
+		}
+		to.Set(ns)
+
+	case reflect.Array:
+		for i := 0; i < from.Len(); i++ {
+			d.copy(from.Index(i), to.Index(i))
+		}
+
+	case reflect.Struct:
+		if !from.CanAddr() {
+			// the unexported fields can be accessed by an addressable value only
+			tmp := reflect.New(from.Type()).Elem()
+			tmp.Set(from)
+			from = tmp
+		}
+		for i := 0; i < from.NumField(); i++ {
+			d.copy(accessibleField(from.Field(i)), accessibleField(to.Field(i)))
+		}
+
+	default:
+		// the scalars, funcs, chans and unsafe pointers
+		to.Set(from)
+	}
+}
@@ -0,0 +1,117 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type dcAccount struct {
+	ID      int
+	Owner   *dcOwner
+	Tags    []string
+	Attrs   map[string]interface{}
+	Created time.Time
+	Any     interface{}
+	Scores  [3]int
+
+	balance float64
+	history []string
+	owner   *dcOwner
+	next    *dcAccount
+}
+
+type dcOwner struct {
+	Name  string
+	email string
+}
+
+func TestDeepCopy(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("DeepCopy: independent", testDeepCopy_independent)
+	t.Run("DeepCopy: cycles and shared pointers", testDeepCopy_cycles)
+	t.Run("DeepCopy: plain values", testDeepCopy_plainValues)
+	t.Run("DeepCopyValue", testDeepCopyValue)
+}
+
+func newDcAccount() *dcAccount {
+	owner := &dcOwner{Name: "tom", email: "tom@example.com"}
+	return &dcAccount{
+		ID:      1,
+		Owner:   owner,
+		Tags:    []string{"a", "b"},
+		Attrs:   map[string]interface{}{"x": []int{1, 2}, "y": map[string]int{"k": 1}},
+		Created: time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local),
+		Any:     &dcOwner{Name: "jerry"},
+		Scores:  [3]int{1, 2, 3},
+		balance: 12.5,
+		history: []string{"open"},
+		owner:   owner,
+	}
+}
+
+func testDeepCopy_independent(t *testing.T) {
+	src := newDcAccount()
+	cp := DeepCopy(src).(*dcAccount)
+
+	if !reflect.DeepEqual(src, cp) {
+		t.Fatalf("expecting equal copy:\n%+v\n%+v", src, cp)
+	}
+	assert.Equal(t, true, cp.Created == src.Created)
+	assert.Equal(t, 12.5, cp.balance)
+
+	// mutate the source deeply, the copy must not be changed
+	src.Owner.Name, src.Owner.email = "x", "x"
+	src.Tags[0] = "x"
+	src.Attrs["x"].([]int)[0] = 100
+	src.Attrs["y"].(map[string]int)["k"] = 100
+	src.Any.(*dcOwner).Name = "x"
+	src.history[0] = "x"
+
+	assert.Equal(t, "tom", cp.Owner.Name)
+	assert.Equal(t, "tom@example.com", cp.owner.email)
+	assert.Equal(t, "a", cp.Tags[0])
+	assert.Equal(t, []int{1, 2}, cp.Attrs["x"])
+	assert.Equal(t, map[string]int{"k": 1}, cp.Attrs["y"])
+	assert.Equal(t, "jerry", cp.Any.(*dcOwner).Name)
+	assert.Equal(t, "open", cp.history[0])
+}
+
+func testDeepCopy_cycles(t *testing.T) {
+	src := newDcAccount()
+	src.next = src
+
+	cp := DeepCopy(src).(*dcAccount)
+	if cp == src || cp.next != cp {
+		t.Fatal("the cycle should be kept in the copy")
+	}
+	if cp.Owner != cp.owner || cp.Owner == src.Owner {
+		t.Fatal("the shared pointer should be kept shared in the copy")
+	}
+}
+
+func testDeepCopy_plainValues(t *testing.T) {
+	assert.Equal(t, nil, DeepCopy(nil))
+	assert.Equal(t, 3, DeepCopy(3))
+	assert.Equal(t, "s", DeepCopy("s"))
+
+	m := map[string][]int{"a": {1}}
+	mc := DeepCopy(m).(map[string][]int)
+	m["a"][0] = 2
+	assert.Equal(t, 1, mc["a"][0])
+
+	a := dcAccount{ID: 2, balance: 3}
+	ac := DeepCopy(a).(dcAccount)
+	assert.Equal(t, 3.0, ac.balance)
+}
+
+func testDeepCopyValue(t *testing.T) {
+	src := newDcAccount()
+	// an unexported field
+	v := reflect.ValueOf(src).Elem().FieldByName("history")
+	cp := DeepCopyValue(v)
+	src.history[0] = "x"
+	assert.Equal(t, []string{"open"}, cp.Interface())
+}
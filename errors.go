@@ -0,0 +1,216 @@
+package ref
+
+import (
+	"fmt"
+	"gopkg.in/hedzr/errors.v2"
+	"reflect"
+	"strings"
+)
+
+type (
+	// CloneError is an error on copying the source value at Path, for
+	// example:
+	//
+	//	field "Orders[2].Price": cannot convert string to float64
+	//
+	// The underlying error could be tested by errors.As, such as
+	// *UnsettableError, *UnsupportedKindError, *ConversionError or
+	// *MethodError.
+	CloneError struct {
+		Path string // the dotted path of the source value, empty for the root object
+		Err  error
+
+		aborted bool // the cloning is aborted, see cloner.abort
+	}
+
+	// CloneErrors is the aggregated errors of a cloning, the cloner
+	// records every failed field and goes on copying the rest fields.
+	CloneErrors []*CloneError
+
+	// UnsettableError means the target value cannot be set, such as
+	// an unexported field or an unaddressable value.
+	UnsettableError struct {
+		Type reflect.Type // the target type
+	}
+
+	// UnsupportedKindError means copying between the kinds of source
+	// and target is not supported.
+	UnsupportedKindError struct {
+		From, To reflect.Type
+	}
+
+	// ConversionError means the source value cannot be converted to
+	// the target type.
+	ConversionError struct {
+		From, To reflect.Type
+		Err      error // the underlying cause, it might be nil
+	}
+
+	// MethodError is an error returned by a getter or a setter, such
+	// as GetX() (T, error) or SetX(v) error. It aborts the cloning.
+	MethodError struct {
+		Method string
+		Err    error
+	}
+
+	// UnsupportedOptionError means the cloning options are given to a
+	// backend (such as JSONCopier) which cannot apply them, see
+	// WithBackend.
+	UnsupportedOptionError struct {
+		Backend Cloner
+	}
+
+	// MergeError is an error on merging the source value at Path, for
+	// example:
+	//
+	//	key "servers[1].port": cannot convert string to int
+	//
+	// The underlying error could be tested by errors.As as CloneError.
+	MergeError struct {
+		Path string // the dotted path of the source value, empty for the root object
+		Err  error
+	}
+
+	// MergeConflictError means a source value conflicts with the target
+	// value at Path, see ConflictError.
+	MergeConflictError struct {
+		Path           string // the dotted path of the source value
+		Target, Source interface{}
+	}
+)
+
+func (e *CloneError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("field %q: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *CloneError) Unwrap() error { return e.Err }
+
+func (e CloneErrors) Error() string {
+	var sb strings.Builder
+	for i, x := range e {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(x.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the first error
+func (e CloneErrors) Unwrap() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e[0]
+}
+
+// Is reports whether any of the errors matches target
+func (e CloneErrors) Is(target error) bool {
+	for _, x := range e {
+		if errors.Is(x, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error which matches target
+func (e CloneErrors) As(target interface{}) bool {
+	for _, x := range e {
+		if errors.As(x, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *UnsettableError) Error() string {
+	return fmt.Sprintf("target (%v) cannot be set", e.Type)
+}
+
+func (e *UnsupportedKindError) Error() string {
+	return fmt.Sprintf("copying from %v (%v) to %v (%v) is not supported", e.From, kindOf(e.From), e.To, kindOf(e.To))
+}
+
+func (e *ConversionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("cannot convert %v to %v: %v", e.From, e.To, e.Err)
+	}
+	return fmt.Sprintf("cannot convert %v to %v", e.From, e.To)
+}
+
+// Unwrap returns the underlying cause
+func (e *ConversionError) Unwrap() error { return e.Err }
+
+func (e *MethodError) Error() string {
+	return fmt.Sprintf("method %s: %v", e.Method, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *MethodError) Unwrap() error { return e.Err }
+
+func (e *UnsupportedOptionError) Error() string {
+	return fmt.Sprintf("the cloning options cannot be applied by the backend %T", e.Backend)
+}
+
+func (e *MergeError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("key %q: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *MergeError) Unwrap() error { return e.Err }
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("conflict at %q: target %v, source %v", e.Path, e.Target, e.Source)
+}
+
+func kindOf(typ reflect.Type) reflect.Kind {
+	if typ == nil {
+		return reflect.Invalid
+	}
+	return typ.Kind()
+}
+
+// annotate returns err as a CloneError at the current path, the
+// innermost CloneError is returned if err has one.
+func (c cloner) annotate(err error) (ce *CloneError) {
+	if err == nil {
+		return nil
+	}
+	if !errors.As(err, &ce) {
+		ce = &CloneError{Path: c.path, Err: err}
+	}
+	return
+}
+
+// fail annotates err with the current path and records it if the
+// cloner is collecting the errors, so that the rest values can be
+// copied still. It returns nil once err is recorded.
+//
+// An aborted error is never recorded, it's returned to the caller
+// so that the cloning stops.
+func (c cloner) fail(err error) error {
+	ce := c.annotate(err)
+	if ce == nil {
+		return nil
+	}
+	if c.errs == nil || ce.aborted {
+		return ce
+	}
+	*c.errs = append(*c.errs, ce)
+	return nil
+}
+
+// abort returns err as an aborted CloneError at the current path
+func (c cloner) abort(err error) error {
+	ce := c.annotate(err)
+	ce.aborted = true
+	return ce
+}
@@ -0,0 +1,225 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"gopkg.in/hedzr/errors.v2"
+	"reflect"
+	"testing"
+)
+
+type errOrder struct {
+	Name  string
+	Price string
+	Qty   string
+}
+
+type errOrderDto struct {
+	Name  string
+	Price float64
+	Qty   int
+}
+
+type errCart struct {
+	Owner  string
+	Orders []errOrder
+}
+
+type errCartDto struct {
+	Owner  string
+	Orders []errOrderDto
+}
+
+func TestCloneE(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("CloneE: ok", testCloneE_ok)
+	t.Run("CloneE: path annotated errors", testCloneE_pathAnnotated)
+	t.Run("CloneE: unsettable target", testCloneE_unsettable)
+	t.Run("MustClone: panics", testMustClone_panics)
+}
+
+func testCloneE_ok(t *testing.T) {
+	var to errCart
+	from := errCart{Owner: "tom", Orders: []errOrder{{Name: "a"}}}
+	if err := CloneE(from, &to); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, from, to)
+}
+
+func testCloneE_pathAnnotated(t *testing.T) {
+	from := errCart{Owner: "tom", Orders: []errOrder{
+		{Name: "a", Price: "1.5", Qty: "2"},
+		{Name: "b", Price: "cheap", Qty: "3"},
+		{Name: "c", Price: "4", Qty: "many"},
+	}}
+	var to errCartDto
+	err := CloneE(from, &to)
+	if err == nil {
+		t.Fatal("expecting an error")
+	}
+	t.Log(err)
+
+	var errs CloneErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expecting CloneErrors but got %T", err)
+	}
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "Orders[1].Price", errs[0].Path)
+	assert.Equal(t, "Orders[2].Qty", errs[1].Path)
+	assert.Equal(t, `field "Orders[1].Price": cannot convert string to float64: strconv.ParseFloat: parsing "cheap": invalid syntax`, errs[0].Error())
+
+	var ce *ConversionError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expecting a ConversionError: %v", err)
+	}
+	assert.Equal(t, "float64", ce.To.String())
+
+	var ue *UnsettableError
+	assert.Equal(t, false, errors.As(err, &ue))
+
+	// the rest fields are still copied
+	assert.Equal(t, "tom", to.Owner)
+	assert.Equal(t, 3, len(to.Orders))
+	assert.Equal(t, errOrderDto{Name: "a", Price: 1.5, Qty: 2}, to.Orders[0])
+	assert.Equal(t, "c", to.Orders[2].Name)
+	assert.Equal(t, 4.0, to.Orders[2].Price)
+}
+
+func testCloneE_unsettable(t *testing.T) {
+	var to errCart
+	err := DefaultCloner.Copy(&errCart{Owner: "tom"}, to)
+	var ue *UnsettableError
+	if !errors.As(err, &ue) {
+		t.Fatalf("expecting an UnsettableError but got %v", err)
+	}
+}
+
+func testMustClone_panics(t *testing.T) {
+	defer func() {
+		if e := recover(); e == nil {
+			t.Fatal("expecting a panic")
+		}
+	}()
+
+	var to errCartDto
+	MustClone(errCart{Orders: []errOrder{{Price: "x"}}}, &to)
+}
+
+func TestMergeErrors(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("path annotated errors", testMergeErrors_pathAnnotated)
+	t.Run("never panics", testMergeErrors_noPanics)
+	t.Run("typed causes", testMergeErrors_typed)
+	t.Run("no false errors", testMergeErrors_noFalseErrors)
+}
+
+func testMergeErrors_pathAnnotated(t *testing.T) {
+	var to errCartDto
+	err := NewMerger(map[string]interface{}{
+		"Owner": "alice",
+		"Orders": []interface{}{
+			map[string]interface{}{"Name": "apple", "Price": "1.5", "Qty": "many"},
+		},
+	}).MergeTo(&to)
+
+	var me *MergeError
+	if !errors.As(err, &me) {
+		t.Fatalf("expecting a MergeError but got %v", err)
+	}
+	assert.Equal(t, "Orders[0].Qty", me.Path)
+	var ce *ConversionError
+	assert.Equal(t, true, errors.As(err, &ce))
+
+	err = NewMerger(map[string]interface{}{"a": map[string]interface{}{"f": func() {}}}).
+		MergeTo(&map[string]map[string]int{})
+	if !errors.As(err, &me) {
+		t.Fatalf("expecting a MergeError but got %v", err)
+	}
+	assert.Equal(t, "a.f", me.Path)
+}
+
+func testMergeErrors_noPanics(t *testing.T) {
+	var i int
+	var nilMap map[string]int
+	for _, c := range []struct {
+		from, to interface{}
+	}{
+		{nil, &i},
+		{map[string]interface{}{"a": 1}, nil},
+		{map[string]interface{}{"a": 1}, nilMap},
+		{map[string]interface{}{"a": make(chan int)}, &map[string]string{}},
+		{map[string]interface{}{"Orders": "x"}, &errCartDto{}},
+		{errCart{Orders: []errOrder{{Qty: "1"}}}, &map[int]int{}},
+		{[]interface{}{1, "a"}, &errOrderDto{}},
+	} {
+		err := NewMerger(c.from).MergeTo(c.to)
+		if err == nil {
+			t.Fatalf("%v -> %T: expecting an error", c.from, c.to)
+		}
+		t.Logf("%v -> %T: %v", c.from, c.to, err)
+	}
+
+	m := map[string]interface{}{"a": 1}
+	assert.NoError(t, NewMerger(map[string]interface{}{"a": nil}).MergeTo(&m))
+	assert.Equal(t, map[string]interface{}{"a": nil}, m)
+}
+
+type errPrivate struct {
+	Name   string
+	secret string
+}
+
+func testMergeErrors_typed(t *testing.T) {
+	var me *MergeError
+	var uk *UnsupportedKindError
+	err := NewMerger(map[string]interface{}{"a": 1}).MergeTo(&map[int]int{})
+	if !errors.As(err, &me) || !errors.As(err, &uk) {
+		t.Fatalf("expecting a MergeError of UnsupportedKindError but got %v", err)
+	}
+	assert.Equal(t, "a", me.Path)
+
+	var ue *UnsettableError
+	mm := NewMerger(errPrivate{Name: "n", secret: "s"})
+	mm.IgnoreUnexportedError = false
+	err = mm.MergeTo(&errPrivate{})
+	if !errors.As(err, &me) || !errors.As(err, &ue) {
+		t.Fatalf("expecting a MergeError of UnsettableError but got %v", err)
+	}
+	assert.Equal(t, "secret", me.Path)
+
+	var ce *ConversionError
+	err = NewMerger(map[string]interface{}{"Orders": []interface{}{map[string]interface{}{"Qty": "x"}}}).MergeTo(&errCartDto{})
+	if !errors.As(err, &me) || !errors.As(err, &ce) {
+		t.Fatalf("expecting a MergeError of ConversionError but got %v", err)
+	}
+	assert.Equal(t, "Orders[0].Qty", me.Path)
+}
+
+func testMergeErrors_noFalseErrors(t *testing.T) {
+	to := map[string][]string{}
+	if err := NewMerger(map[string][]string{"a": {"x"}}).MergeTo(&to); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string][]string{"a": {"x"}}, to)
+
+	// an invalid target is made, merged and set by setTo
+	var set Value
+	m := NewMerger(nil)
+	err := m.mergeStructTo(ValueOf(errOrder{Name: "n"}), Value{}, reflect.TypeOf(&errOrder{}), func(val Value) Value {
+		set = val
+		return val
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &errOrder{Name: "n"}, set.GetValue())
+	assert.Error(t, m.mergeStructTo(ValueOf(errOrder{}), Value{}, reflect.TypeOf(errOrder{}), nil))
+
+	var me *MergeError
+	err = NewMerger(map[string]interface{}{"s": []int{1}}, WithSliceStrategy(SliceByKey)).
+		MergeTo(&map[string]interface{}{"s": []int{2}})
+	if !errors.As(err, &me) {
+		t.Fatalf("expecting a MergeError but got %v", err)
+	}
+	assert.Equal(t, "s", me.Path)
+}
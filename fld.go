@@ -29,3 +29,12 @@ func SetUnexportedField(field reflect.Value, value interface{}) {
 		Elem().
 		Set(reflect.ValueOf(value))
 }
+
+// accessibleField returns a value of the addressable field which can
+// be read and set freely, even if it's an unexported field.
+func accessibleField(field reflect.Value) reflect.Value {
+	if field.CanInterface() {
+		return field
+	}
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
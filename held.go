@@ -59,6 +59,14 @@ func (h *heldStruct) Get(sourceField reflect.StructField, toName string) reflect
 	return h.field
 }
 
+// GetByIndex is a fast version of Get, it locates the target field by
+// its index rather than its name.
+func (h *heldStruct) GetByIndex(sourceField reflect.StructField, targetField reflect.StructField) reflect.Value {
+	h.sourceField, h.fieldName = sourceField, targetField.Name
+	h.field = h.targetObj.FieldByIndex(targetField.Index)
+	return h.field
+}
+
 func newHeldMap(to reflect.Value) held {
 	h := &heldMap{targetObj: to}
 	switch h.targetObj.Kind() {
@@ -92,6 +100,6 @@ func (h *heldMap) Set(newVal reflect.Value)                 { h.targetObj.SetMap
 func (h *heldMap) GetSourceField() (sf reflect.StructField) { return h.sourceField }
 func (h *heldMap) Get(sourceField reflect.StructField, toName string) reflect.Value {
 	h.sourceField = sourceField
-	h.keyName = reflect.ValueOf(toName)
+	h.keyName = reflect.ValueOf(toName).Convert(h.targetObj.Type().Key())
 	return h.targetObj.MapIndex(h.keyName)
 }
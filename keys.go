@@ -0,0 +1,201 @@
+package ref
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DefaultKeyTagNames are the struct tag names which are used for
+// matching the map keys to the struct fields, on cloning or merging
+// a map into a struct. See also WithKeyTagNames and
+// Merger.KeyTagNames.
+//
+// A key matches a field by, in order:
+//
+//  1. the name in the struct tags, such as `json:"user_name"`;
+//  2. the field name, such as UserName;
+//  3. the normalized forms of them, which are case-insensitive and
+//     ignore the underscores and hyphens, so "user_name",
+//     "user-name", "userName" and "USERNAME" match UserName.
+//
+// The field with tag "-" is never matched.
+var DefaultKeyTagNames = []string{"json", "yaml", "mapstructure"}
+
+// keyMatcher finds the exported fields (including the promoted
+// fields of embedded structs) of a struct type by the map keys.
+type keyMatcher struct {
+	exact      map[string]reflect.StructField
+	normalized map[string]reflect.StructField
+}
+
+type keyMatcherKey struct {
+	typ      reflect.Type
+	tagNames string
+}
+
+var keyMatchers sync.Map // map[keyMatcherKey]*keyMatcher
+
+// keyMatcherOf returns the cached keyMatcher of a struct type
+func keyMatcherOf(typ reflect.Type, tagNames []string) *keyMatcher {
+	key := keyMatcherKey{typ, strings.Join(tagNames, ",")}
+	if km, ok := keyMatchers.Load(key); ok {
+		return km.(*keyMatcher)
+	}
+	km := buildKeyMatcher(typ, tagNames)
+	actual, _ := keyMatchers.LoadOrStore(key, km)
+	return actual.(*keyMatcher)
+}
+
+// maxEmbedDepth limits the depth of the embedded structs to be walked
+// by buildKeyMatcher.
+const maxEmbedDepth = 32
+
+// keyCandidate is a field which has a name at the depth in walking
+type keyCandidate struct {
+	sf reflect.StructField
+	n  int // the count of fields which have the name
+}
+
+// buildKeyMatcher walks the fields breadth-first as the Go selector
+// rules: a shallower field hides the deeper ones which have the same
+// name, and the fields having the same name at the same depth hide
+// each other. The embedded struct types which have been walked are
+// skipped, so a recursive embedding (type Node struct{ *Node }) stops.
+func buildKeyMatcher(typ reflect.Type, tagNames []string) *keyMatcher {
+	km := &keyMatcher{
+		exact:      make(map[string]reflect.StructField),
+		normalized: make(map[string]reflect.StructField),
+	}
+	hiddenExact, hiddenNormalized := make(map[string]bool), make(map[string]bool)
+
+	type level struct {
+		typ   reflect.Type
+		index []int
+	}
+	visited := make(map[reflect.Type]bool)
+	count, nextCount := map[reflect.Type]int{typ: 1}, make(map[reflect.Type]int)
+	var next []level
+	for depth, queue := 0, []level{{typ, nil}}; len(queue) > 0 && depth < maxEmbedDepth; depth++ {
+		exact, normalized := make(map[string]*keyCandidate), make(map[string]*keyCandidate)
+		for _, lv := range queue {
+			if visited[lv.typ] {
+				continue
+			}
+			visited[lv.typ] = true
+			// a type embedded more than once at this depth makes its
+			// fields ambiguous
+			dup := count[lv.typ] > 1
+
+			for i := 0; i < lv.typ.NumField(); i++ {
+				sf := lv.typ.Field(i)
+				sf.Index = append(append([]int{}, lv.index...), i)
+
+				names, skip := fieldKeyNames(sf, tagNames)
+				if skip {
+					continue
+				}
+				if sf.Anonymous && len(names) == 1 {
+					// no name in tags, promote the fields of the embedded struct
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct && !visited[ft] {
+						if nextCount[ft]++; nextCount[ft] == 1 {
+							next = append(next, level{ft, sf.Index})
+						}
+					}
+				}
+				if sf.PkgPath != "" {
+					continue // unexported
+				}
+				for _, name := range names {
+					addKeyCandidate(exact, name, sf, dup)
+					if n := normalizeKey(name); n != "" {
+						addKeyCandidate(normalized, n, sf, dup)
+					}
+				}
+			}
+		}
+
+		settleKeyCandidates(km.exact, hiddenExact, exact)
+		settleKeyCandidates(km.normalized, hiddenNormalized, normalized)
+		queue, next = next, nil
+		count, nextCount = nextCount, make(map[reflect.Type]int)
+	}
+	return km
+}
+
+func addKeyCandidate(candidates map[string]*keyCandidate, name string, sf reflect.StructField, dup bool) {
+	c, ok := candidates[name]
+	if !ok {
+		c = &keyCandidate{sf: sf}
+		candidates[name] = c
+	}
+	if !ok || !reflect.DeepEqual(c.sf.Index, sf.Index) {
+		c.n++
+	}
+	if dup {
+		c.n++
+	}
+}
+
+// settleKeyCandidates adds the candidates of a depth into fields unless
+// their names have been settled at a shallower depth, the ambiguous
+// names are hidden.
+func settleKeyCandidates(fields map[string]reflect.StructField, hidden map[string]bool, candidates map[string]*keyCandidate) {
+	for name, c := range candidates {
+		if _, ok := fields[name]; ok || hidden[name] {
+			continue
+		}
+		if c.n == 1 {
+			fields[name] = c.sf
+		} else {
+			hidden[name] = true
+		}
+	}
+}
+
+// fieldKeyNames returns the tag names and the field name of a field
+func fieldKeyNames(sf reflect.StructField, tagNames []string) (names []string, skip bool) {
+	for _, tn := range tagNames {
+		tag, ok := sf.Tag.Lookup(tn)
+		if !ok {
+			continue
+		}
+		name := strings.TrimSpace(strings.Split(tag, ",")[0])
+		if name == "-" {
+			return nil, true
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	names = append(names, sf.Name)
+	return
+}
+
+// lookup finds the field for a map key
+func (km *keyMatcher) lookup(key string) (sf reflect.StructField, ok bool) {
+	if sf, ok = km.exact[key]; !ok {
+		sf, ok = km.normalized[normalizeKey(key)]
+	}
+	return
+}
+
+// normalizeKey returns the lower-case form of a key without the
+// underscores, hyphens and spaces, so that the snake_case, kebab-case,
+// camelCase and PascalCase keys can be matched each other.
+func normalizeKey(key string) string {
+	var sb strings.Builder
+	for _, r := range key {
+		switch r {
+		case '_', '-', ' ':
+		default:
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,155 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"reflect"
+	"testing"
+)
+
+type keyedBase struct {
+	CreatedBy string `yaml:"created_by"`
+}
+
+type keyedConfig struct {
+	keyedBase
+	UserName   string
+	MaxRetries int    `json:"max_retries"`
+	LogLevel   string `yaml:"log-level"`
+	DBHost     string `mapstructure:"database_host"`
+	Timeout    int    `toml:"time_out"`
+	Secret     string `json:"-"`
+}
+
+func TestKeyMatching(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("normalizeKey", testKeyMatching_normalizeKey)
+	t.Run("clone: map -> struct", testKeyMatching_clone)
+	t.Run("clone: custom tag", testKeyMatching_customTag)
+	t.Run("merge: map -> struct", testKeyMatching_merge)
+	t.Run("recursive embedding", testKeyMatching_recursive)
+	t.Run("ambiguous fields", testKeyMatching_ambiguous)
+}
+
+func testKeyMatching_normalizeKey(t *testing.T) {
+	for _, k := range []string{"user_name", "user-name", "userName", "UserName", "USERNAME", "user name"} {
+		assert.Equal(t, "username", normalizeKey(k))
+	}
+}
+
+func keyedMap() map[string]interface{} {
+	return map[string]interface{}{
+		"user_name":     "tom",
+		"max_retries":   3,
+		"log-level":     "debug",
+		"database_host": "db",
+		"created_by":    "admin",
+		"time_out":      5,
+		"secret":        "s",
+		"Secret":        "s",
+	}
+}
+
+func checkKeyedConfig(t *testing.T, cfg keyedConfig) {
+	assert.Equal(t, "tom", cfg.UserName)
+	assert.Equal(t, 3, cfg.MaxRetries)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "db", cfg.DBHost)
+	assert.Equal(t, "admin", cfg.CreatedBy)
+	assert.Equal(t, "", cfg.Secret)
+}
+
+func testKeyMatching_clone(t *testing.T) {
+	var cfg keyedConfig
+	if err := CloneE(keyedMap(), &cfg); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	checkKeyedConfig(t, cfg)
+	assert.Equal(t, 5, cfg.Timeout) // by the normalized field name
+
+	var cfg2 keyedConfig
+	Clone(map[string]interface{}{"MAXRETRIES": 1, "dbhost": "h", "LOG_LEVEL": "info"}, &cfg2)
+	assert.Equal(t, 1, cfg2.MaxRetries)
+	assert.Equal(t, "h", cfg2.DBHost)
+	assert.Equal(t, "info", cfg2.LogLevel)
+}
+
+func testKeyMatching_customTag(t *testing.T) {
+	var cfg keyedConfig
+	Clone(map[string]interface{}{"time_out": 9, "max_retries": 2}, &cfg, WithKeyTagNames("toml"))
+	assert.Equal(t, 9, cfg.Timeout)
+	assert.Equal(t, 2, cfg.MaxRetries) // normalized field name still works
+}
+
+func testKeyMatching_merge(t *testing.T) {
+	var cfg keyedConfig
+	if err := NewMerger(keyedMap()).MergeTo(&cfg); err != nil {
+		t.Fatalf("merge map error: %v", err)
+	}
+	checkKeyedConfig(t, cfg)
+
+	var cfg2 keyedConfig
+	mm := NewMerger(map[string]interface{}{"time_out": 7})
+	mm.KeyTagNames = []string{"toml"}
+	if err := mm.MergeTo(&cfg2); err != nil {
+		t.Fatalf("merge map error: %v", err)
+	}
+	assert.Equal(t, 7, cfg2.Timeout)
+}
+
+type keyedNode struct {
+	*keyedNode
+	Name string
+}
+
+type KeyedNodeA struct{ *KeyedNodeB }
+type KeyedNodeB struct {
+	*KeyedNodeA
+	Value int
+}
+
+func testKeyMatching_recursive(t *testing.T) {
+	var n keyedNode
+	if err := CloneE(map[string]interface{}{"Name": "x"}, &n); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, "x", n.Name)
+
+	var a KeyedNodeA
+	if err := CloneE(map[string]interface{}{"value": 1}, &a); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, 1, a.Value)
+}
+
+type keyedLeft struct {
+	ID   int
+	Note string
+}
+
+type keyedRight struct {
+	ID    int
+	Extra string
+}
+
+type keyedBoth struct {
+	keyedLeft
+	keyedRight
+}
+
+func testKeyMatching_ambiguous(t *testing.T) {
+	km := buildKeyMatcher(reflect.TypeOf(keyedBoth{}), nil)
+	_, ok := km.lookup("ID")
+	assert.Equal(t, false, ok) // both ID are at the same depth
+	_, ok = km.lookup("id")
+	assert.Equal(t, false, ok)
+	sf, ok := km.lookup("note")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, []int{0, 1}, sf.Index)
+
+	var b keyedBoth
+	if err := CloneE(map[string]interface{}{"ID": 1, "Note": "n", "Extra": "e"}, &b); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, keyedBoth{keyedLeft{Note: "n"}, keyedRight{Extra: "e"}}, b)
+}
@@ -13,9 +13,22 @@ import (
 
 type (
 	Merger struct {
-		m                     Value
+		m                     Value   // the first source
+		sources               []Value // the more sources, see Add
 		ec                    *errors.WithCauses
 		IgnoreUnexportedError bool
+		KeyTagNames           []string // the struct tag names for matching the map keys, see DefaultKeyTagNames
+
+		sliceRule  sliceRule // the default strategy for merging slices, see WithSliceStrategy
+		slicePaths []slicePathRule
+		path       string // the dotted path of the source value in merging
+
+		conflict ConflictPolicy // see WithConflictPolicy
+		resolver ConflictResolver
+
+		layer      int               // the index of source in merging
+		provenance map[string]int    // the path -> the index of source which set it, see Provenance
+		provKeys   map[string]string // the normalized path -> the path in provenance
 	}
 
 	context struct {
@@ -30,11 +43,13 @@ type (
 //     var targetMap map[string]interface{}
 //     NewMerger(m).MergeTo(&targetMap)
 //
-func NewMerger(inputMap interface{}) *Merger {
+// The options such as WithConflictPolicy could be specified too.
+func NewMerger(inputMap interface{}, opts ...MergeOption) *Merger {
 	mm := &Merger{
 		m:                     ValueOf(inputMap),
 		ec:                    errors.NewContainer(""),
 		IgnoreUnexportedError: true,
+		KeyTagNames:           DefaultKeyTagNames,
 	}
 	//if mm.m.Kind() != reflect.Map {
 	//	mm.ec.Attach(errors.New("inputMap MUST BE a map object or its ref.Value representation"))
@@ -44,6 +59,9 @@ func NewMerger(inputMap interface{}) *Merger {
 	//		mm.ec.Attach(errors.New("inputMap MUST BE a map[string]... object"))
 	//	}
 	//}
+	for _, opt := range opts {
+		opt(mm)
+	}
 	return mm
 }
 
@@ -52,18 +70,85 @@ func NewMerger(inputMap interface{}) *Merger {
 func (m *Merger) HasError() bool { return !m.ec.IsEmpty() }
 func (m *Merger) Reset()         { m.ec = errors.NewContainer("") }
 
+// MergeTo merges the sources into to in order, which should be a
+// pointer. It never panics, a failure is returned as a *MergeError with
+// the key path of the source value, such as "servers[1].port".
+//
+// The errors of a previous MergeTo are cleared, and a failed source
+// doesn't stop merging the rest sources, see also Add and Provenance.
 func (m *Merger) MergeTo(to interface{}) (err error) {
-	if m.ec.IsEmpty() {
-		m.ec.Attach(m.merge(m.m, ValueOf(to)))
+	m.Reset()
+	m.provenance, m.provKeys = make(map[string]int), make(map[string]string)
+	tv := ValueOf(to)
+	for i, src := range append([]Value{m.m}, m.sources...) {
+		m.layer = i
+		m.ec.Attach(m.merge(src, tv))
 	}
 	return m.ec.Error()
 }
 
+// merge never panics, a panic on merging (such as by reflect) is
+// recovered as a *MergeError at the path where it happened.
 func (m *Merger) merge(from, to Value) (err error) {
-	err = m.impl(newContext(from, to))
+	defer func() {
+		if e := recover(); e != nil {
+			cause, ok := e.(error)
+			if !ok {
+				cause = errors.New("%v", e)
+			}
+			err = m.annotate(cause)
+			m.path = ""
+		}
+	}()
+
+	if !from.IsValid() || !to.IsValid() {
+		return &MergeError{Err: errors.New("cannot merge from or into an invalid value")}
+	}
+	err = m.annotate(m.impl(newContext(from, to)))
 	return
 }
 
+// enter appends name to the current path, the returned func restores
+// the previous path, and annotates the error of merging the value at
+// the path, see Merger.annotate.
+//
+//	leave := m.enter(field.Name)
+//	err = leave(m.mergeFieldToField(...))
+func (m *Merger) enter(name string) (leave func(err error) error) {
+	saved := m.path
+	m.path = joinPath(saved, name)
+	return func(err error) error {
+		err = m.annotate(err)
+		m.path = saved
+		return err
+	}
+}
+
+// enterIndex appends an index to the current path, see enter.
+func (m *Merger) enterIndex(index int) (leave func(err error) error) {
+	saved := m.path
+	m.path = indexPath(saved, index)
+	return func(err error) error {
+		err = m.annotate(err)
+		m.path = saved
+		return err
+	}
+}
+
+// annotate wraps err with the current path as a *MergeError, unless it
+// has been annotated at a deeper path.
+func (m *Merger) annotate(err error) error {
+	if err == nil {
+		return nil
+	}
+	var me *MergeError
+	var ce *MergeConflictError
+	if errors.As(err, &me) || errors.As(err, &ce) {
+		return err
+	}
+	return &MergeError{Path: m.path, Err: err}
+}
+
 func (m *Merger) impl(c *context) (err error) {
 	//if !c.from.Type().AssignableTo(c.to.Type()) {
 	//	err = errors.New("cannot assign from %v to %v", c.from.Type(), c.to.Type())
@@ -74,6 +159,20 @@ func (m *Merger) impl(c *context) (err error) {
 	//	c.to = ValueOf(c.to.GetValue())
 	//}
 
+	if !c.to.IsValid() {
+		// allocate the nil pointers, such as merging into a nil *T
+		if c.to = allocIndirect(c.toOrig); !c.to.IsValid() || c.to.Kind() == reflect.Ptr {
+			err = &UnsettableError{}
+			if c.toOrig.IsValid() {
+				err = &UnsettableError{Type: c.toOrig.Type()}
+			}
+			return
+		}
+	}
+	if isTextConvertible(c.from.Type(), c.to.Type()) {
+		return m.mergeText(c.from, c.to, c.to.Type())
+	}
+
 	fromKind := c.from.Kind()
 	switch fromKind {
 	// case reflect.Ptr: // never
@@ -84,10 +183,15 @@ func (m *Merger) impl(c *context) (err error) {
 		err = m.mergeStructTo(c.from, c.to, c.to.Type(), nil)
 		return
 	case reflect.Map:
+		if k := c.to.Kind(); k != reflect.Map && k != reflect.Struct {
+			err = m.mergeMapTo(c.from, c.to, c.to.Type(), nil)
+			return
+		}
 		mapKeys := c.from.MapKeys()
 		for _, key := range mapKeys {
 			val := c.from.MapIndex(key)
-			err = m.mergeValInto(c, key, val, c.to)
+			leave := m.enter(mapKeyString(key))
+			err = leave(m.mergeValInto(c, key, val, c.to))
 			if err != nil {
 				return
 			}
@@ -97,26 +201,17 @@ func (m *Merger) impl(c *context) (err error) {
 		if c.from.Type().AssignableTo(c.to.Type()) {
 			//log.Debugf("        copying %v -> %v, simple set.", from.Type(), tot)
 			defer m.deferRecoverFunc(&err, func(e interface{}) error {
-				return errors.New("failed on copying %v -> %v (a), simple set. inner error: %v", c.from.Type(), c.to.Type(), e)
-			})
-			c.to.Set(c.from.Value)
-			return
-		} else if c.from.Kind() == c.to.Kind() {
-			// log.Debugf("        copying %v -> %v, simple set.", from.Type(), to.Type())
-			defer m.deferRecoverFunc(&err, func(e interface{}) error {
-				return errors.New("failed on copying %v -> %v (a), simple set. inner error: %v", c.from.Type(), c.to.Type(), e)
-			})
-			c.to.Set(c.from.Value)
+				return &UnsettableError{Type: c.to.Type()}
+			})()
+			err = m.setScalar(c.to.Value, c.from.Value, c.to.Type(), c.to.Set)
 			return
 		} else {
 			var out reflect.Value
 			if out, err = tryConvert(c.from.Value, c.to.Type()); err == nil {
-				c.to.Set(out)
-				return
+				err = m.setScalar(c.to.Value, out, c.to.Type(), c.to.Set)
 			}
 		}
 	}
-	err = errors.New("cannot merge from %v to %v (not impl)", c.from.Type(), c.to.Type())
 	return
 }
 
@@ -130,16 +225,14 @@ func (m *Merger) mergeValInto(c *context, key, val reflect.Value, to Value) (err
 		err = m.mergeValIntoSlice(c, key, value, to)
 	case reflect.Struct:
 		err = m.mergeValIntoStruct(c, key, value, to)
-	case reflect.Ptr:
-		err = errors.New("cannot merge val into ptr %v", to.Type())
 	default:
-		err = errors.New("cannot merge val into %v,%v", to.Kind(), to.Type())
+		err = &UnsupportedKindError{From: typeOf(value), To: to.Type()}
 	}
 	return
 }
 
 func (m *Merger) mergeValIntoSlice(c *context, key reflect.Value, val, toSlice Value) (err error) {
-	err = errors.New("cannot merge val into slice %v", toSlice.Type())
+	err = &UnsupportedKindError{From: typeOf(val), To: toSlice.Type()}
 	return
 }
 
@@ -152,74 +245,72 @@ func (m *Merger) mergeValIntoStruct(c *context, key reflect.Value, value, toStru
 	} else if vk, ok := v1v.(interface{ String() string }); ok {
 		v1key = vk.String()
 	} else {
-		err = errors.New("expecting key is a stringer but it's %v", key.Type())
+		err = &UnsupportedKindError{From: key.Type(), To: toStruct.Type()}
 		return
 	}
 
-	if toFieldType, ok := toStruct.Type().FieldByName(v1key); ok {
-		toField := toStruct.FieldByName(v1key)
-		err = m.mergeValIntoStructField(c, key, value, toStruct, Value{toField}, toFieldType)
-	} else {
-		v1key = Captalize(v1key)
-		if toFieldType, ok = toStruct.Type().FieldByName(v1key); ok {
-			toField := toStruct.FieldByName(v1key)
+	if toFieldType, ok := keyMatcherOf(toStruct.Type(), m.KeyTagNames).lookup(v1key); ok {
+		if toField, ok := fieldByIndexAlloc(toStruct.Value, toFieldType.Index); ok {
 			err = m.mergeValIntoStructField(c, key, value, toStruct, Value{toField}, toFieldType)
 		}
-		// err = errors.New("no field %q found in target struct", v1key)
 	}
+	// err = errors.New("no field %q found in target struct", v1key)
 	return
 }
 
 func (m *Merger) mergeValIntoStructField(c *context, key reflect.Value, val, toStruct, toField Value, toFieldType reflect.StructField) (err error) {
+	if isTextConvertible(val.Type(), toFieldType.Type) {
+		return m.mergeText(val, toField, toFieldType.Type)
+	}
+
 	switch val.Kind() {
 	case reflect.Map:
-		err = m.mergeMapToStructField(key, val, toField, toFieldType)
+		return m.mergeMapToStructField(key, val, toField, toFieldType)
 	case reflect.Slice, reflect.Array:
-		err = m.mergeSliceToStructField(key, val, toField, toFieldType)
+		return m.mergeSliceToStructField(key, val, toField, toFieldType)
 	case reflect.Struct:
-		err = m.mergeStructToStructField(key, val, toField, toFieldType)
+		return m.mergeStructToStructField(key, val, toField, toFieldType)
 	// case reflect.Ptr:
 	default:
 		if val.Type().AssignableTo(toFieldType.Type) {
-			toField.Set(val.Value)
+			err = m.setScalar(toField.Value, val.Value, toFieldType.Type, toField.Set)
 			log.Debugf("        > merged %v (%v) -> field %q (%v)", val.GetValue(), val.Type(), toFieldType.Name, toFieldType.Type)
 			return
 		} else {
 			var out reflect.Value
-			out, err = tryConvert(val.Value, toFieldType.Type)
-			if err == nil {
-				toField.Set(out)
-				return
+			if out, err = tryConvert(val.Value, toFieldType.Type); err == nil {
+				err = m.setScalar(toField.Value, out, toFieldType.Type, toField.Set)
 			}
-			err = errors.New("cannot merge val into struct field %q, %v", toFieldType.Name, toFieldType.Type).Attach(err)
 			return
 		}
 	}
-	err = errors.New("cannot merge val into struct field %q, %v", toFieldType.Name, toFieldType.Type)
-	return
 }
 
 func (m *Merger) mergeMapToStructField(key reflect.Value, val, toField Value, toFieldType reflect.StructField) (err error) {
-	err = errors.New("cannot merge map into field %q (%v)", toFieldType.Name, toFieldType.Type)
+	err = m.mergeElem(val.Value, toField.Value)
 	return
 }
 
 func (m *Merger) mergeSliceToStructField(key reflect.Value, val, toField Value, toFieldType reflect.StructField) (err error) {
-	err = errors.New("cannot merge slice into field %q (%v)", toFieldType.Name, toFieldType.Type)
+	err = m.mergeElem(val.Value, toField.Value)
 	return
 }
 
 func (m *Merger) mergeStructToStructField(key reflect.Value, val, toField Value, toFieldType reflect.StructField) (err error) {
-	err = errors.New("cannot merge struct into field %q (%v)", toFieldType.Name, toFieldType.Type)
+	err = m.mergeElem(val.Value, toField.Value)
 	return
 }
 
 func (m *Merger) mergeValIntoMap(c *context, key reflect.Value, val, tgtMap Value) (err error) {
 	if !key.Type().AssignableTo(tgtMap.Type().Key()) {
-		err = errors.New("cannot assign map[%v] to map[%v]", key.Type(), tgtMap.Type().Key())
+		err = &UnsupportedKindError{From: key.Type(), To: tgtMap.Type().Key()}
 		return
 	}
 
+	if !val.IsValid() {
+		// a nil interface{}, such as {"key": nil}
+		val = Value{reflect.Zero(tgtMap.Type().Elem())}
+	}
 	valKind := val.Kind()
 	var v1 = Value{key}
 	//var v2 = Value{val}
@@ -231,33 +322,47 @@ func (m *Merger) mergeValIntoMap(c *context, key reflect.Value, val, tgtMap Valu
 	log.Debugf("    copying field '%v' (value=%v)...", v1.GetValue(), val.GetValue())
 
 	if tgtMap.IsNil() {
+		if !tgtMap.CanAddr() {
+			return &UnsettableError{Type: tgtMap.Type()}
+		}
 		//log.Debugf("tgtMap's ptr = %v", tgtMap.Addr().Type())
 		newMap := reflect.MakeMap(tgtMap.Type())
 		tgtMap.Addr().Elem().Set(newMap)
 	}
 
 	switch valKind {
-	case reflect.Bool:
-		tgtMap.SetMapIndex(key, val.Value)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		tgtMap.SetMapIndex(key, val.Value)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		tgtMap.SetMapIndex(key, val.Value)
-	case reflect.Float32, reflect.Float64:
-		tgtMap.SetMapIndex(key, val.Value)
-	case reflect.Complex64, reflect.Complex128:
-		tgtMap.SetMapIndex(key, val.Value)
-	case reflect.String:
-		tgtMap.SetMapIndex(key, val.Value)
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64,
+		reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		err = m.mergeScalarIntoMap(key, val, tgtMap)
 	case reflect.Slice, reflect.Array:
 		err = m.mergeSliceInto(v1, val, tgtMap)
 	case reflect.Map:
 		err = m.mergeMapInto(v1, val, tgtMap)
 	case reflect.Ptr:
 		err = m.mergePtrInto(v1, val, tgtMap)
+	case reflect.Struct:
+		err = m.mergeElemInto(v1, val, tgtMap)
 	default:
-		panic(errors.New("copying into map[%v], unknown source type %v (kind=%v, value=%v)", v1.GetValue(), val.Type(), valKind, val.GetValue()))
+		// the nil interfaces, funcs and chans are set as is
+		err = m.mergeScalarIntoMap(key, val, tgtMap)
+	}
+	return
+}
+
+func (m *Merger) mergeScalarIntoMap(key reflect.Value, val, tgtMap Value) (err error) {
+	et, out := tgtMap.Type().Elem(), val.Value
+	if !out.Type().AssignableTo(et) {
+		if out, err = tryConvert(out, et); err != nil {
+			return
+		}
 	}
+	err = m.setScalar(tgtMap.MapIndex(key), out, et, func(v reflect.Value) {
+		tgtMap.SetMapIndex(key, v)
+	})
 	return
 }
 
@@ -269,17 +374,14 @@ func (m *Merger) mergeSliceInto(key, valSlice Value, tgtMap Value) (err error) {
 	zero := vtReal.IsZero()
 
 	if valid && !zero && vtReal.Kind() != reflect.Slice {
-		err = errors.New("mergeSliceInto: target (map['%v']) is not a slice (type=%v, zero=%v, valid=%v).", key.GetValue(), vtReal.Type(), zero, valid)
+		err = &UnsupportedKindError{From: valSlice.Type(), To: vtReal.Type()}
 		return
 	}
 
 	if !valid || zero {
-		var l int
-		if vt.IsValid() {
-			l = vt.Len()
-		}
-		log.Debugf("        > target slice is empty or invalid, simple put. len=%v. vt=%v, key=%v (%v). %v|%v", l, vt.GetValue(), key.GetValue(), key.Type(), tgtMap.GetValue().(map[string]interface{})["e"], tgtMap.Type())
+		log.Debugf("        > target slice is empty or invalid, simple put. key=%v (%v). %v", key.GetValue(), key.Type(), tgtMap.Type())
 		tgtMap.SetMapIndex(key.Value, valSlice.Value)
+		m.record()
 		return
 	}
 
@@ -328,10 +430,27 @@ func (m *Merger) mergeSliceInto(key, valSlice Value, tgtMap Value) (err error) {
 //	return
 //}
 
+// mergeElemInto merges a struct or map into the map entry tgtMap[key],
+// the entry is made if it doesn't exist.
+func (m *Merger) mergeElemInto(key, val Value, tgtMap Value) (err error) {
+	nv := reflect.New(tgtMap.Type().Elem()).Elem()
+	if vt := tgtMap.MapIndex(key.Value); vt.IsValid() {
+		nv.Set(vt)
+	}
+	if err = m.mergeElem(val.Value, nv); err == nil {
+		tgtMap.SetMapIndex(key.Value, nv)
+	}
+	return
+}
+
 func (m *Merger) mergeMapInto(key, valMap Value, tgtMap Value) (err error) {
 	vt := tgtMap.MapIndex(key.Value)
 	if !vt.IsValid() || vt.IsNil() {
+		if !valMap.Type().AssignableTo(tgtMap.Type().Elem()) {
+			return m.mergeElemInto(key, valMap, tgtMap)
+		}
 		tgtMap.SetMapIndex(key.Value, valMap.Value)
+		m.record()
 		return
 	}
 
@@ -348,6 +467,7 @@ func (m *Merger) mergePtrInto(key, val Value, tgtMap Value) (err error) {
 		log.Debugf("        tmp target is: %v; %+v", val.Type(), Value{target}.GetValue())
 		if err = DefaultCloner.Copy(vv, target); err == nil {
 			tgtMap.SetMapIndex(key.Value, target.Elem())
+			m.record()
 		} else {
 			log.Errorf("copying ptr to ptr not ok: %v", err)
 		}
@@ -366,7 +486,7 @@ func (m *Merger) mergePtrInto(key, val Value, tgtMap Value) (err error) {
 
 func (m *Merger) mergeObjIntoObj(from, to Value, toType reflect.Type, setTo func(val Value) Value) (err error) {
 	if !from.IsValid() {
-		err = errors.New("invalid source value: %v", from.Type())
+		err = &UnsupportedKindError{To: toType}
 		return
 	}
 
@@ -383,23 +503,26 @@ func (m *Merger) mergeObjIntoObj(from, to Value, toType reflect.Type, setTo func
 	case reflect.Map: // impossible entry
 	case reflect.Ptr: // impossible entry
 	default:
-		panic(errors.New("not implemented for source type: %v %v", fk, from.Type()))
+		err = m.mergeSettable(to, setTo, func(to reflect.Value) error {
+			return m.mergeElem(from.Value, to)
+		})
 	}
 	return
 }
 
 func (m *Merger) mergeStructTo(from, to Value, toType reflect.Type, setTo func(val Value) Value) (err error) {
 	if !to.IsValid() {
+		// a new target is made and merged, and then it's set by setTo
+		if setTo == nil {
+			return &UnsettableError{Type: toType}
+		}
 		newTargetType, newToOrig, newTo := m.indirectCreate(toType)
 		log.Debugf("        . newTargetType=%v, newToOrig.type=%v, newTo.type=%v", newTargetType, newToOrig.Type(), newTo.Type())
-		// no := reflect.New(toType)
-		defer m.deferRecoverFunc(&err, func(e interface{}) error {
-			return errors.New("mergeStructTo: failed in setting new elem (type=%v) into 'to': %v", toType, e)
-		})
-		if setTo != nil {
-			to = setTo(newTo)
-		} else {
-			to.Addr().Elem().Set(newTo.Value)
+		if err = m.mergeStructTo(from, newTo, newTargetType, nil); err == nil {
+			if toType.Kind() == reflect.Ptr {
+				newTo = newToOrig
+			}
+			setTo(newTo)
 		}
 		return
 	}
@@ -408,15 +531,68 @@ func (m *Merger) mergeStructTo(from, to Value, toType reflect.Type, setTo func(v
 	switch tk {
 	case reflect.Struct:
 		err = m.mergeStructToStruct(from, to, toType, setTo)
-	case reflect.Slice: // TODO struct->slice
-		panic(errors.New("mergeStructTo: not implemented for source type: %v %v", tk, from.Type()))
-	case reflect.Map: // TODO struct->map
-		panic(errors.New("mergeStructTo: not implemented for source type: %v %v", tk, from.Type()))
-	case reflect.Ptr: // TODO struct->ptr
-		panic(errors.New("mergeStructTo: not implemented for source type: %v %v", tk, from.Type()))
+	case reflect.Map:
+		err = m.mergeSettable(to, setTo, func(to reflect.Value) error {
+			return m.mergeStructToMap(from, Value{to})
+		})
+	case reflect.Slice, reflect.Array:
+		err = m.mergeSliceToSlice(sliceOf(from), to, setTo)
+	case reflect.Ptr, reflect.Interface:
+		err = m.mergeSettable(to, setTo, func(to reflect.Value) error {
+			return m.mergeElem(from.Value, to)
+		})
 	default:
-		// TODO struct->others primitive types
-		panic(errors.New("mergeStructTo: not implemented for source type: %v %v", tk, from.Type()))
+		err = &UnsupportedKindError{From: from.Type(), To: to.Type()}
+	}
+	return
+}
+
+// mergeStructToMap merges the exported fields of a struct into the
+// settable map to, the keys are the names in the struct tags or the
+// field names, see Merger.KeyTagNames. The fields of the embedded
+// structs are promoted, and the zero (or nil) fields are unset.
+func (m *Merger) mergeStructToMap(from, to Value) (err error) {
+	kt := to.Type().Key()
+	if kt.Kind() != reflect.String && kt.Kind() != reflect.Interface {
+		return &UnsupportedKindError{From: from.Type(), To: to.Type()}
+	}
+	if to.IsNil() {
+		to.Set(reflect.MakeMap(to.Type()))
+	}
+
+	for i := 0; i < from.NumField(); i++ {
+		sf := from.Type().Field(i)
+		names, skip := fieldKeyNames(sf, m.KeyTagNames)
+		if skip {
+			continue
+		}
+
+		fv := from.Field(i)
+		if sf.Anonymous && len(names) == 1 {
+			if ev := IndirectValueRecursive(fv); ev.Kind() == reflect.Struct {
+				if err = m.mergeStructToMap(Value{ev}, to); err != nil {
+					return
+				}
+				continue
+			}
+		}
+		if !isExportableField(sf) {
+			continue
+		}
+		fv = interfaceToRealType(Value{fv}).Value
+		if !fv.IsValid() || fv.IsZero() {
+			continue
+		}
+
+		key := reflect.ValueOf(names[0])
+		if kt.Kind() == reflect.String {
+			key = key.Convert(kt)
+		}
+		leave := m.enter(sf.Name)
+		err = leave(m.mergeValIntoMap(nil, key, Value{fv}, to))
+		if err != nil {
+			return
+		}
 	}
 	return
 }
@@ -441,7 +617,7 @@ func (m *Merger) mergeStructToStruct(from, to Value, toType reflect.Type, setTo
 		field := from.Type().Field(i)
 		if !isExportableField(field) {
 			if !m.IgnoreUnexportedError {
-				err = errors.New("target cannot be set (unexported field): field %q (src-value: %v)", field.Name, from.GetValue())
+				err = m.enter(field.Name)(&UnsettableError{Type: field.Type})
 				return
 			}
 			continue
@@ -467,7 +643,10 @@ func (m *Merger) mergeStructToStruct(from, to Value, toType reflect.Type, setTo
 			continue
 		}
 
-		if err = m.mergeFieldToField(Value{from.Field(i)}, Value{to.FieldByName(toName)}, field, tot, setTo); err != nil {
+		// the fields are set directly, setTo is for the struct itself
+		leave := m.enter(field.Name)
+		err = leave(m.mergeFieldToField(Value{from.Field(i)}, Value{to.FieldByName(toName)}, field, tot, nil))
+		if err != nil {
 			return
 		}
 	}
@@ -476,7 +655,12 @@ func (m *Merger) mergeStructToStruct(from, to Value, toType reflect.Type, setTo
 
 func (m *Merger) mergeFieldToField(fromV, toV Value, srcField reflect.StructField, tot reflect.Type, setTo func(val Value) Value) (err error) {
 	if !fromV.IsValid() {
-		err = errors.New("invalid source value: %v", fromV.Type())
+		err = &UnsupportedKindError{To: tot}
+		return
+	}
+	if fromV.IsZero() {
+		// a zero (or nil) field of a struct source is unset, it never
+		// overwrites the target nor conflicts with it
 		return
 	}
 	toType := tot
@@ -492,20 +676,23 @@ func (m *Merger) mergeFieldToField(fromV, toV Value, srcField reflect.StructFiel
 			toType = toV.Elem().Type()
 			log.Debugf("        .. toType %v", toType)
 		} else {
-			err = errors.New("invalid target value: %v", toV.Type())
+			err = &UnsettableError{Type: tot}
 			return
 		}
 	}
-	if fromV.IsZero() {
-		if setTo != nil {
-			setTo(fromV)
-		} else {
-			toV.IndirectValue().SetZero()
+	from, to := fromV.IndirectValueRecursive(), toV.IndirectValueRecursive()
+	if !from.IsValid() {
+		return // a nil pointee, such as **T -> *nil
+	}
+	if !to.IsValid() {
+		if to = allocIndirect(toV); !to.IsValid() || to.Kind() == reflect.Ptr {
+			return &UnsettableError{Type: toV.Type()}
 		}
-		return
+	}
+	if isTextConvertible(from.Type(), toType) {
+		return m.mergeText(from, to, toType)
 	}
 
-	from, to := fromV.IndirectValueRecursive(), toV.IndirectValueRecursive()
 	fk, tk := from.Kind(), toType.Kind()
 	switch fk {
 	case reflect.Struct:
@@ -515,54 +702,63 @@ func (m *Merger) mergeFieldToField(fromV, toV Value, srcField reflect.StructFiel
 	case reflect.Map:
 		err = m.mergeMapTo(from, to, toType, setTo)
 	case reflect.Ptr:
-		panic(errors.New("not implemented for source type: %v %v", tk, from.Type()))
+		err = &UnsupportedKindError{From: from.Type(), To: toType}
 	default:
 		if from.Type().AssignableTo(toType) {
 			//log.Debugf("        copying %v -> %v, simple set.", from.Type(), tot)
 			defer m.deferRecoverFunc(&err, func(e interface{}) error {
-				return errors.New("failed on copying field %q (%v) %v -> %v (a), simple set. inner error: %v", srcField.Name, srcField.Type, from.Type(), toType, e)
-			})
-			if setTo != nil {
-				setTo(from)
-			} else {
-				to.Set(from.Value)
-			}
-		} else if fk == tk {
-			// log.Debugf("        copying %v -> %v, simple set.", from.Type(), to.Type())
-			defer m.deferRecoverFunc(&err, func(e interface{}) error {
-				return errors.New("failed on copying field %q (%v) %v -> %v (b), simple set. inner error: %v", srcField.Name, srcField.Type, from.Type(), toType, e)
-			})
-			if setTo != nil {
-				setTo(from)
-			} else {
-				to.Set(from.Value)
-			}
+				return &UnsettableError{Type: toType}
+			})()
+			err = m.setScalar(to.Value, from.Value, toType, m.setter(to, setTo))
 		} else {
 			var out reflect.Value
 			if out, err = tryConvert(from.Value, toType); err == nil {
-				to.Set(out)
+				err = m.setScalar(to.Value, out, toType, m.setter(to, setTo))
 			} else {
 				log.Debugf("        copying field %q (%v) %v -> %v (tk=%v), simple set.", srcField.Name, srcField.Type, from.Type(), toType, tk)
-				panic(errors.New("not implemented for source type: %v %v", fk, from.Type()))
 			}
 		}
 	}
 	return
 }
 
+// mergeText converts from to toType through the text interfaces, such
+// as "127.0.0.1" -> net.IP, see also TryConvert.
+func (m *Merger) mergeText(from, to Value, toType reflect.Type) (err error) {
+	var out reflect.Value
+	if out, err = tryConvert(from.Value, toType); err == nil {
+		err = m.setScalar(to.Value, out, toType, to.Set)
+	}
+	return
+}
+
+// setter returns a func to set the target to, by setTo if it's not nil
+func (m *Merger) setter(to Value, setTo func(val Value) Value) func(v reflect.Value) {
+	if setTo != nil {
+		return func(v reflect.Value) { setTo(Value{v}) }
+	}
+	return to.Set
+}
+
 func (m *Merger) mergeMapTo(from, to Value, tot reflect.Type, setTo func(val Value) Value) (err error) {
-	switch tot.Kind() {
+	tk := tot.Kind()
+	if to.IsValid() {
+		tk = to.Kind()
+	}
+	switch tk {
 	case reflect.Map:
 		err = m.mergeMapToMap(from, to)
-		return
-	case reflect.Ptr:
-	case reflect.Slice, reflect.Array:
 	case reflect.Struct:
 		err = m.mergeMapToStruct(from, to)
-		return
+	case reflect.Slice, reflect.Array:
+		err = m.mergeSliceToSlice(sliceOf(from), to, setTo)
+	case reflect.Ptr, reflect.Interface:
+		err = m.mergeSettable(to, setTo, func(to reflect.Value) error {
+			return m.mergeElem(from.Value, to)
+		})
 	default:
+		err = &UnsupportedKindError{From: from.Type(), To: tot}
 	}
-	panic(errors.New("not implemented for source type: %v (tot: %v)", from.Type(), tot))
 	return
 }
 
@@ -579,58 +775,76 @@ func (m *Merger) mergeMapToStruct(from, to Value) (err error) {
 }
 
 func (m *Merger) mergeSliceTo(from, to Value, tot reflect.Type, setTo func(val Value) Value) (err error) {
-	switch tot.Kind() {
-	case reflect.Struct:
+	tk := tot.Kind()
+	if to.IsValid() {
+		tk = to.Kind()
+	}
+	switch tk {
 	case reflect.Slice, reflect.Array:
 		err = m.mergeSliceToSlice(from, to, setTo)
-		return
-	case reflect.Map:
-	case reflect.Ptr:
+	case reflect.Struct, reflect.Map, reflect.Ptr, reflect.Interface:
+		// the elements are merged into the target one by one, so the
+		// latter ones win
+		err = m.mergeSettable(to, setTo, func(to reflect.Value) (err error) {
+			for i := 0; i < from.Len() && err == nil; i++ {
+				leave := m.enterIndex(i)
+				err = leave(m.mergeElem(from.Index(i), to))
+			}
+			return
+		})
 	default:
+		err = &UnsupportedKindError{From: from.Type(), To: tot}
 	}
-	panic(errors.New("not implemented for source type: %v (tot: %v)", from.Type(), tot))
 	return
 }
 
-func (m *Merger) mergeSliceToSlice(from, to Value, setTo func(val Value) Value) (err error) {
-	seen := make(map[comparison]bool)
-	for si := 0; si < from.Len(); si++ {
-		sv := from.Index(si)
-		var found bool
-		for i := 0; i < to.Len(); i++ {
-			v := to.Index(i)
-			//log.Debugf("        - comparing '%v' and '%v'", Value{sv}.GetValue(), Value{v}.GetValue())
-			if equal(v, sv, seen) {
-				//log.Debugf("          matched")
-				found = true
-				break
-			}
-		}
-		if !found {
-			ns := reflect.Append(to.Value, sv)
-			if setTo != nil {
-				to = Value{ns}
-				to = setTo(to)
-			} else {
-				to.Set(ns)
-			}
-			//tgtMap.SetMapIndex(key.Value, realTarget.Value)
-			//log.Debugf("        new slice: %v", tgtMap.MapIndex(key.Value))
+// mergeSettable calls merge with the target to if it's settable (or a
+// non-nil map), or else with a settable copy of it which is set back by
+// setTo.
+func (m *Merger) mergeSettable(to Value, setTo func(val Value) Value, merge func(to reflect.Value) error) (err error) {
+	if setTo == nil {
+		// a non-nil map is updated in place
+		if !to.CanSet() && (to.Kind() != reflect.Map || to.IsNil()) {
+			return &UnsettableError{Type: to.Type()}
 		}
+		return merge(to.Value)
+	}
+	nv := reflect.New(to.Type()).Elem()
+	nv.Set(to.Value)
+	if err = merge(nv); err == nil {
+		setTo(Value{nv})
 	}
 	return
 }
 
+// typeOf returns the type of v, or nil if v is invalid (a nil
+// interface value)
+func typeOf(v Value) reflect.Type {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Type()
+}
+
+// sliceOf returns a slice which holds v only
+func sliceOf(v Value) Value {
+	s := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+	s.Index(0).Set(v.Value)
+	return Value{s}
+}
+
+// deferRecoverFunc returns a func to be deferred, which recovers a
+// panic as an error annotated with the current path, the non-error
+// panic values are turned into errors by buildError.
 func (m *Merger) deferRecoverFunc(err *error, buildError func(e interface{}) error) func() {
 	return func() {
 		if e := recover(); e != nil {
-			if e2, ok := e.(error); ok {
-				*err = e2
-			} else {
-				*err = buildError(e)
-				// *err = errors.New("%v: failed in setting new elem (type=%v) into 'to': %v", title, toType, e)
-				log.Errorf("recovering: %v", *err)
+			cause, ok := e.(error)
+			if !ok {
+				cause = buildError(e)
 			}
+			*err = m.annotate(cause)
+			log.Errorf("recovering: %v", *err)
 		}
 	}
 }
@@ -657,6 +871,21 @@ func (m *Merger) indirectCreate(fromType reflect.Type) (newTargetType reflect.Ty
 	return
 }
 
+// allocIndirect indirects v recursively and allocates the nil settable
+// pointers, the last nil pointer is returned if it's not settable.
+func allocIndirect(v Value) Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !v.CanSet() {
+				break
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = Value{v.Elem()}
+	}
+	return v
+}
+
 func interfaceToRealType(v Value) Value {
 	if v.Kind() == reflect.Interface {
 		v = ValueOf(v.GetValue())
@@ -0,0 +1,114 @@
+package ref
+
+import "reflect"
+
+// ConflictPolicy specifies which value wins when Merger merges a source
+// value into a scalar target which has a different value already, see
+// WithConflictPolicy.
+type ConflictPolicy int
+
+const (
+	// ConflictOverride sets the source value into the target, it's the
+	// default policy.
+	ConflictOverride ConflictPolicy = iota
+	// ConflictKeep keeps the target value
+	ConflictKeep
+	// ConflictError stops the merging with a *MergeConflictError
+	ConflictError
+)
+
+// ConflictResolver decides the value of a conflicted target at path,
+// dst is the target value and src is the source value which has been
+// converted to the target type. The returned value is converted to the
+// target type if necessary, and an invalid reflect.Value keeps the
+// target as is. A non-nil error stops the merging.
+type ConflictResolver func(path string, dst, src reflect.Value) (reflect.Value, error)
+
+// MergeOption is an option of NewMerger
+type MergeOption func(m *Merger)
+
+// WithConflictPolicy specifies the policy for the conflicted scalar
+// values (the bool, numbers, strings and the text convertible types).
+// A conflict means the target has a non-zero value which differs from
+// the source one, the zero fields of a struct source are unset so that
+// they never conflict. For example, the layers of configuration could be
+// merged with an explicit precedence:
+//
+//	var cfg Config
+//	_ = ref.NewMerger(flags).MergeTo(&cfg)
+//	_ = ref.NewMerger(env, ref.WithConflictPolicy(ref.ConflictKeep)).MergeTo(&cfg)
+//	_ = ref.NewMerger(defaults, ref.WithConflictPolicy(ref.ConflictKeep)).MergeTo(&cfg)
+func WithConflictPolicy(policy ConflictPolicy) MergeOption {
+	return func(m *Merger) {
+		m.conflict = policy
+	}
+}
+
+// WithConflictResolver specifies a callback to resolve the conflicted
+// scalar values, it's preferred to the conflict policy.
+//
+//	ref.NewMerger(src, ref.WithConflictResolver(func(path string, dst, src reflect.Value) (reflect.Value, error) {
+//		if path == "replicas" && dst.Int() > src.Int() {
+//			return dst, nil
+//		}
+//		return src, nil
+//	})).MergeTo(&spec)
+func WithConflictResolver(resolver ConflictResolver) MergeOption {
+	return func(m *Merger) {
+		m.resolver = resolver
+	}
+}
+
+// setScalar sets the source value src, which has been converted to the
+// target type toType, by set if there is no conflict with the target
+// value dst, or else the conflict is resolved by the policy. The value
+// is not set if it equals dst.
+func (m *Merger) setScalar(dst, src reflect.Value, toType reflect.Type, set func(v reflect.Value)) (err error) {
+	if dst.IsValid() && equal(dst, src, make(map[comparison]bool)) {
+		return // nothing changes, see Merger.Provenance
+	}
+	v, keep, err := m.resolveConflict(dst, src)
+	if err != nil || keep {
+		return
+	}
+	if !v.Type().AssignableTo(toType) {
+		if v, err = tryConvert(v, toType); err != nil {
+			return
+		}
+	}
+	set(v)
+	m.record()
+	return
+}
+
+// resolveConflict returns the value to be set, keep means the target
+// keeps its value.
+func (m *Merger) resolveConflict(dst, src reflect.Value) (v reflect.Value, keep bool, err error) {
+	for dst.IsValid() && dst.Kind() == reflect.Interface {
+		dst = dst.Elem()
+	}
+	if !dst.IsValid() || dst.IsZero() || equal(dst, src, make(map[comparison]bool)) {
+		return src, false, nil
+	}
+
+	if m.resolver != nil {
+		v, err = m.resolver(m.path, dst, src)
+		keep = err == nil && !v.IsValid()
+		return
+	}
+
+	switch m.conflict {
+	case ConflictKeep:
+		keep = true
+	case ConflictError:
+		err = &MergeConflictError{Path: m.path, Target: dst.Interface(), Source: src.Interface()}
+	default:
+		v = src
+	}
+	return
+}
+
+// isScalarKind tests whether k is bool, a numeric kind or string
+func isScalarKind(k reflect.Kind) bool {
+	return k == reflect.Bool || k == reflect.String || isNumberKind(k)
+}
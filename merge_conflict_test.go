@@ -0,0 +1,136 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"gopkg.in/hedzr/errors.v2"
+	"reflect"
+	"testing"
+)
+
+func TestMergeConflicts(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("policies", testMergeConflict_policies)
+	t.Run("layers", testMergeConflict_layers)
+	t.Run("error", testMergeConflict_error)
+	t.Run("resolver", testMergeConflict_resolver)
+}
+
+type layeredConfig struct {
+	Host  string
+	Port  int
+	Debug bool
+}
+
+func testMergeConflict_policies(t *testing.T) {
+	for _, c := range []struct {
+		policy    ConflictPolicy
+		expect    layeredConfig
+		expectMap map[string]interface{}
+	}{
+		{ConflictOverride, layeredConfig{Host: "remote", Port: 8080, Debug: true}, map[string]interface{}{"a": 2, "b": 3}},
+		{ConflictKeep, layeredConfig{Host: "local", Port: 8080, Debug: true}, map[string]interface{}{"a": 1, "b": 3}},
+	} {
+		to := layeredConfig{Host: "local", Debug: true}
+		err := NewMerger(map[string]interface{}{
+			"Host":  "remote",
+			"Port":  "8080",
+			"Debug": true,
+		}, WithConflictPolicy(c.policy)).MergeTo(&to)
+		if err != nil {
+			t.Fatalf("policy %v: %v", c.policy, err)
+		}
+		assert.Equal(t, c.expect, to)
+
+		tm := map[string]interface{}{"a": 1}
+		err = NewMerger(map[string]interface{}{"a": 2, "b": 3}, WithConflictPolicy(c.policy)).MergeTo(&tm)
+		if err != nil {
+			t.Fatalf("policy %v: %v", c.policy, err)
+		}
+		assert.Equal(t, c.expectMap, tm)
+	}
+}
+
+func testMergeConflict_layers(t *testing.T) {
+	defaults := layeredConfig{Host: "localhost", Port: 80}
+	file := layeredConfig{Host: "example.com"}
+	flags := layeredConfig{Port: 8080, Debug: true}
+
+	// from the highest precedence to the lowest one
+	var cfg layeredConfig
+	for _, layer := range []layeredConfig{flags, file, defaults} {
+		if err := NewMerger(layer, WithConflictPolicy(ConflictKeep)).MergeTo(&cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, layeredConfig{Host: "example.com", Port: 8080, Debug: true}, cfg)
+
+	// the same result from the lowest one to the highest one, the zero
+	// fields of the layers are unset
+	cfg = layeredConfig{}
+	for _, layer := range []layeredConfig{defaults, file, flags} {
+		if err := NewMerger(layer).MergeTo(&cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, layeredConfig{Host: "example.com", Port: 8080, Debug: true}, cfg)
+}
+
+func testMergeConflict_error(t *testing.T) {
+	to := layeredConfig{Host: "local", Port: 80}
+	err := NewMerger(map[string]interface{}{"Port": 80, "Host": "local"}, WithConflictPolicy(ConflictError)).MergeTo(&to)
+	assert.NoError(t, err)
+
+	// the zero fields of a struct source are unset, they don't conflict
+	err = NewMerger(layeredConfig{Host: "local"}, WithConflictPolicy(ConflictError)).MergeTo(&to)
+	assert.NoError(t, err)
+	assert.Equal(t, layeredConfig{Host: "local", Port: 80}, to)
+
+	err = NewMerger(map[string]interface{}{"Port": "81"}, WithConflictPolicy(ConflictError)).MergeTo(&to)
+	var ce *MergeConflictError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expecting a MergeConflictError but got %v", err)
+	}
+	assert.Equal(t, "Port", ce.Path)
+	assert.Equal(t, 80, ce.Target)
+	assert.Equal(t, 81, ce.Source)
+	assert.Equal(t, 80, to.Port)
+
+	tm := map[string]interface{}{"servers": map[string]interface{}{"a": 1}}
+	err = NewMerger(map[string]interface{}{"servers": map[string]interface{}{"a": 2}}, WithConflictPolicy(ConflictError)).MergeTo(&tm)
+	if !errors.As(err, &ce) {
+		t.Fatalf("expecting a MergeConflictError but got %v", err)
+	}
+	assert.Equal(t, "servers.a", ce.Path)
+}
+
+func testMergeConflict_resolver(t *testing.T) {
+	var paths []string
+	resolver := func(path string, dst, src reflect.Value) (reflect.Value, error) {
+		paths = append(paths, path)
+		switch path {
+		case "Port": // the greater one wins
+			if dst.Int() > src.Int() {
+				return dst, nil
+			}
+		case "Host":
+			return reflect.ValueOf(dst.String() + "," + src.String()), nil
+		case "Debug":
+			return reflect.Value{}, nil
+		}
+		return src, nil
+	}
+
+	to := layeredConfig{Host: "h1", Port: 8080, Debug: true}
+	err := NewMerger(layeredConfig{Host: "h2", Port: 80}, WithConflictResolver(resolver)).MergeTo(&to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, layeredConfig{Host: "h1,h2", Port: 8080, Debug: true}, to)
+	assert.Equal(t, []string{"Host", "Port"}, paths) // the zero Debug is unset
+
+	err = NewMerger(layeredConfig{Port: 1}, WithConflictResolver(func(path string, dst, src reflect.Value) (reflect.Value, error) {
+		return reflect.Value{}, errors.New("conflict at %v", path)
+	})).MergeTo(&to)
+	assert.Error(t, err)
+}
@@ -0,0 +1,324 @@
+package ref
+
+import (
+	"gopkg.in/hedzr/errors.v2"
+	"reflect"
+)
+
+// SliceStrategy specifies how a source slice is merged into an existing
+// target slice by Merger, see WithSliceStrategy.
+type SliceStrategy int
+
+const (
+	// SliceAppendUnique appends the source elements which are not in
+	// the target yet, it's the default strategy.
+	SliceAppendUnique SliceStrategy = iota
+	// SliceReplace replaces the target slice with the source one
+	SliceReplace
+	// SliceAppend appends all of the source elements to the target
+	SliceAppend
+	// SlicePrepend inserts all of the source elements before the target
+	// elements
+	SlicePrepend
+	// SliceIndexWise merges each source element into the target element
+	// at the same index, the rest source elements are appended.
+	SliceIndexWise
+	// SliceByKey matches the source and target elements by a key field
+	// (or a map key for the map elements), such as "ID", the matched
+	// elements are merged deeply and the others are appended.
+	SliceByKey
+)
+
+// sliceRule is a slice strategy with its key field
+type sliceRule struct {
+	strategy SliceStrategy
+	key      string // the key field for SliceByKey
+}
+
+// slicePathRule is a slice rule for the slices at a dotted path pattern
+type slicePathRule struct {
+	pattern pathPattern
+	sliceRule
+}
+
+// WithSliceStrategy specifies the default strategy for merging the
+// slices, the keyField is required by SliceByKey. For example:
+//
+//	ref.NewMerger(patch, ref.WithSliceStrategy(ref.SliceByKey, "ID")).MergeTo(&cfg)
+//
+// The target slices are replaced by the source ones if they're empty.
+// A target array is always merged by SliceIndexWise.
+func WithSliceStrategy(strategy SliceStrategy, keyField ...string) MergeOption {
+	return func(m *Merger) {
+		m.sliceRule = newSliceRule(strategy, keyField)
+	}
+}
+
+// WithSliceStrategyAt specifies the strategy for merging the slices at
+// a dotted path pattern, it's preferred to the default strategy. The
+// path is built from the map keys and the struct field names, such as
+// "servers", "spec.containers[*].ports", see also WithIgnoredPaths.
+// The path is matched case-insensitively, and the underscores and
+// hyphens are ignored, as Merger.SourceOf does.
+//
+//	ref.NewMerger(overlay,
+//		ref.WithSliceStrategyAt("plugins", ref.SliceAppend),
+//		ref.WithSliceStrategyAt("servers", ref.SliceByKey, "name"),
+//	).MergeTo(&base)
+func WithSliceStrategyAt(path string, strategy SliceStrategy, keyField ...string) MergeOption {
+	return func(m *Merger) {
+		m.slicePaths = append(m.slicePaths, slicePathRule{compilePathPattern(normalizeKey(path)), newSliceRule(strategy, keyField)})
+	}
+}
+
+func newSliceRule(strategy SliceStrategy, keyField []string) (r sliceRule) {
+	r.strategy = strategy
+	if len(keyField) > 0 {
+		r.key = keyField[0]
+	}
+	return
+}
+
+// sliceRuleAt returns the slice rule for the current path
+func (m *Merger) sliceRuleAt() sliceRule {
+	path := normalizeKey(m.path)
+	for _, r := range m.slicePaths {
+		if r.pattern.Match(path) {
+			return r.sliceRule
+		}
+	}
+	return m.sliceRule
+}
+
+func (m *Merger) mergeSliceToSlice(from, to Value, setTo func(val Value) Value) (err error) {
+	rule := m.sliceRuleAt()
+	if to.Kind() == reflect.Array {
+		_, err = m.mergeSliceIndexWise(from, to)
+		return
+	}
+
+	// the target slice is copied so that its backing array (which
+	// might be shared with others) is never modified.
+	ns := reflect.MakeSlice(to.Type(), to.Len(), to.Len()+from.Len())
+	reflect.Copy(ns, to.Value)
+
+	switch rule.strategy {
+	case SliceReplace:
+		ns = ns.Slice(0, 0)
+		ns, err = m.appendElems(ns, from, 0)
+	case SliceAppend:
+		ns, err = m.appendElems(ns, from, 0)
+	case SlicePrepend:
+		var head reflect.Value
+		if head, err = m.appendElems(reflect.MakeSlice(to.Type(), 0, ns.Cap()), from, 0); err == nil {
+			ns = reflect.AppendSlice(head, ns)
+		}
+	case SliceIndexWise:
+		ns, err = m.mergeSliceIndexWise(from, Value{ns})
+	case SliceByKey:
+		ns, err = m.mergeSliceByKey(from, ns, rule.key)
+	default:
+		ns, err = m.mergeSliceUnique(from, ns)
+	}
+	if err != nil {
+		return
+	}
+
+	if setTo != nil {
+		setTo(Value{ns})
+	} else {
+		to.Set(ns)
+	}
+	m.record()
+	return
+}
+
+// mergeSliceUnique appends the source elements which are not in ns
+func (m *Merger) mergeSliceUnique(from Value, ns reflect.Value) (reflect.Value, error) {
+	seen := make(map[comparison]bool)
+	for si := 0; si < from.Len(); si++ {
+		sv := from.Index(si)
+		var found bool
+		for i := 0; i < ns.Len(); i++ {
+			if equal(ns.Index(i), sv, seen) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ev, err := m.sliceElem(sv, ns.Type().Elem(), ns.Len())
+			if err != nil {
+				return ns, err
+			}
+			ns = reflect.Append(ns, ev)
+		}
+	}
+	return ns, nil
+}
+
+// appendElems appends the source elements from the index start to ns
+func (m *Merger) appendElems(ns reflect.Value, from Value, start int) (reflect.Value, error) {
+	for si := start; si < from.Len(); si++ {
+		ev, err := m.sliceElem(from.Index(si), ns.Type().Elem(), ns.Len())
+		if err != nil {
+			return ns, err
+		}
+		ns = reflect.Append(ns, ev)
+	}
+	return ns, nil
+}
+
+// mergeSliceIndexWise merges the source elements into the elements of
+// the settable slice (or array) ns at the same indices, the rest of
+// source elements are appended if ns is a slice.
+func (m *Merger) mergeSliceIndexWise(from, ns Value) (reflect.Value, error) {
+	n := from.Len()
+	if n > ns.Len() {
+		n = ns.Len()
+	}
+	for i := 0; i < n; i++ {
+		leave := m.enterIndex(i)
+		err := leave(m.mergeElem(from.Index(i), ns.Index(i)))
+		if err != nil {
+			return ns.Value, err
+		}
+	}
+	if ns.Kind() == reflect.Array {
+		return ns.Value, nil
+	}
+	return m.appendElems(ns.Value, from, n)
+}
+
+// mergeSliceByKey merges the source elements into the elements of ns
+// which have the same key, the others are appended.
+func (m *Merger) mergeSliceByKey(from Value, ns reflect.Value, key string) (reflect.Value, error) {
+	if key == "" {
+		return ns, &MergeError{Path: m.path, Err: errors.New("merging slice by key: no key field specified")}
+	}
+
+	index := make(map[interface{}]int)
+	for i := 0; i < ns.Len(); i++ {
+		if k, ok := m.elemKey(ns.Index(i), key); ok {
+			if _, dup := index[k]; !dup {
+				index[k] = i
+			}
+		}
+	}
+
+	for si := 0; si < from.Len(); si++ {
+		sv := from.Index(si)
+		if k, ok := m.elemKey(sv, key); ok {
+			if i, found := index[k]; found {
+				leave := m.enterIndex(i)
+				err := leave(m.mergeElem(sv, ns.Index(i)))
+				if err != nil {
+					return ns, err
+				}
+				continue
+			}
+			index[k] = ns.Len()
+		}
+		ev, err := m.sliceElem(sv, ns.Type().Elem(), ns.Len())
+		if err != nil {
+			return ns, err
+		}
+		ns = reflect.Append(ns, ev)
+	}
+	return ns, nil
+}
+
+// elemKey returns the value of key field of a struct element, or the
+// value of key in a map element, the map key is matched as the struct
+// field name if there's no exact one. The key must be comparable.
+func (m *Merger) elemKey(v reflect.Value, key string) (k interface{}, ok bool) {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	var kv reflect.Value
+	switch v.Kind() {
+	case reflect.Struct:
+		sf, found := keyMatcherOf(v.Type(), m.KeyTagNames).lookup(key)
+		if !found {
+			return
+		}
+		kv = v
+		for _, i := range sf.Index {
+			if kv.Kind() == reflect.Ptr {
+				if kv.IsNil() {
+					return
+				}
+				kv = kv.Elem()
+			}
+			kv = kv.Field(i)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		if kv = v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())); !kv.IsValid() {
+			// match the key field of a struct element, such as "id" -> ID
+			for _, mk := range v.MapKeys() {
+				if normalizeKey(mk.String()) == normalizeKey(key) {
+					kv = v.MapIndex(mk)
+					break
+				}
+			}
+		}
+	}
+
+	for kv.IsValid() && kv.Kind() == reflect.Interface && !kv.IsNil() {
+		kv = kv.Elem()
+	}
+	if !kv.IsValid() || !kv.CanInterface() || !kv.Type().Comparable() {
+		return
+	}
+	return kv.Interface(), true
+}
+
+// sliceElem returns the source element as an element of type et, a new
+// element is made if it cannot be assigned to et.
+func (m *Merger) sliceElem(from reflect.Value, et reflect.Type, index int) (reflect.Value, error) {
+	if from.Type().AssignableTo(et) {
+		return from, nil
+	}
+	leave := m.enterIndex(index)
+	nv := reflect.New(et).Elem()
+	err := leave(m.mergeElem(from, nv))
+	return nv, err
+}
+
+// mergeElem merges a source element into the settable target element
+func (m *Merger) mergeElem(from, to reflect.Value) (err error) {
+	for from.Kind() == reflect.Interface && !from.IsNil() {
+		from = from.Elem()
+	}
+
+	switch to.Kind() {
+	case reflect.Interface:
+		if to.IsNil() || !isMergeableKind(from.Kind()) || !isMergeableKind(to.Elem().Kind()) {
+			if !from.Type().AssignableTo(to.Type()) {
+				return &ConversionError{From: from.Type(), To: to.Type()}
+			}
+			to.Set(from)
+			m.record()
+			return
+		}
+		// merge into a copy of the existing value, the maps are shared
+		nv := reflect.New(to.Elem().Type()).Elem()
+		nv.Set(to.Elem())
+		if err = m.impl(newContext(Value{from}, Value{nv})); err == nil {
+			to.Set(nv)
+		}
+		return
+	case reflect.Ptr:
+		if to.IsNil() {
+			to.Set(reflect.New(to.Type().Elem()))
+		}
+	case reflect.Map:
+		if to.IsNil() {
+			to.Set(reflect.MakeMap(to.Type()))
+		}
+	}
+	return m.impl(newContext(Value{from}, Value{to}))
+}
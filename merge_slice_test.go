@@ -0,0 +1,129 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"testing"
+)
+
+func TestMergeSliceStrategies(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("strategies", testMergeSlice_strategies)
+	t.Run("by key", testMergeSlice_byKey)
+	t.Run("per path", testMergeSlice_perPath)
+	t.Run("index-wise", testMergeSlice_indexWise)
+}
+
+func testMergeSlice_strategies(t *testing.T) {
+	for _, c := range []struct {
+		strategy SliceStrategy
+		expect   []interface{}
+	}{
+		{SliceAppendUnique, []interface{}{"a", "b", "c"}},
+		{SliceReplace, []interface{}{"b", "c"}},
+		{SliceAppend, []interface{}{"a", "b", "b", "c"}},
+		{SlicePrepend, []interface{}{"b", "c", "a", "b"}},
+		{SliceIndexWise, []interface{}{"b", "c"}},
+	} {
+		orig := []interface{}{"a", "b"}
+		to := map[string]interface{}{"tags": orig}
+		err := NewMerger(map[string]interface{}{"tags": []interface{}{"b", "c"}},
+			WithSliceStrategy(c.strategy)).MergeTo(&to)
+		if err != nil {
+			t.Fatalf("strategy %v: %v", c.strategy, err)
+		}
+		assert.Equal(t, c.expect, to["tags"])
+		assert.Equal(t, []interface{}{"a", "b"}, orig)
+	}
+}
+
+type mergeServer struct {
+	ID   int    `json:"id"`
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type mergeCluster struct {
+	Name    string
+	Servers []mergeServer
+	Ports   []int
+}
+
+func testMergeSlice_byKey(t *testing.T) {
+	to := mergeCluster{Name: "c1", Servers: []mergeServer{{1, "h1", 80}, {2, "h2", 80}}}
+	from := mergeCluster{Name: "c1", Servers: []mergeServer{{2, "h2", 8080}, {3, "h3", 90}}}
+	if err := NewMerger(from, WithSliceStrategy(SliceByKey, "ID")).MergeTo(&to); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []mergeServer{{1, "h1", 80}, {2, "h2", 8080}, {3, "h3", 90}}, to.Servers)
+
+	// the key is matched by the json tag too, and the map elements are
+	// matched by the map key
+	var servers []*mergeServer
+	servers = append(servers, &mergeServer{ID: 7, Host: "h7"})
+	err := NewMerger([]interface{}{map[string]interface{}{"id": 7, "host": "n7", "port": 70}},
+		WithSliceStrategy(SliceByKey, "id")).MergeTo(&servers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(servers))
+	assert.Equal(t, mergeServer{7, "n7", 70}, *servers[0])
+
+	assert.Error(t, NewMerger(from, WithSliceStrategy(SliceByKey)).MergeTo(&to))
+}
+
+func testMergeSlice_perPath(t *testing.T) {
+	base := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"id": 1, "port": 80},
+			map[string]interface{}{"id": 2, "port": 80},
+		},
+		"plugins": []interface{}{"x"},
+		"nested":  map[string]interface{}{"tags": []interface{}{"p", "q"}},
+	}
+	overlay := map[string]interface{}{
+		"servers": []interface{}{map[string]interface{}{"id": 2, "port": 8080}},
+		"plugins": []interface{}{"y", "x"},
+		"nested":  map[string]interface{}{"tags": []interface{}{"r"}},
+	}
+	err := NewMerger(overlay,
+		WithSliceStrategyAt("servers", SliceByKey, "id"),
+		WithSliceStrategyAt("plugins", SliceAppend),
+		WithSliceStrategyAt("nested.*", SliceReplace),
+	).MergeTo(&base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": 1, "port": 80},
+		map[string]interface{}{"id": 2, "port": 8080},
+	}, base["servers"])
+	assert.Equal(t, []interface{}{"x", "y", "x"}, base["plugins"])
+	assert.Equal(t, map[string]interface{}{"tags": []interface{}{"r"}}, base["nested"])
+
+	// the paths are matched case-insensitively as Merger.SourceOf
+	to := mergeCluster{Servers: []mergeServer{{1, "h1", 80}}}
+	err = NewMerger(mergeCluster{Servers: []mergeServer{{1, "h1", 8080}}},
+		WithSliceStrategyAt("servers", SliceByKey, "id"),
+	).MergeTo(&to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []mergeServer{{1, "h1", 8080}}, to.Servers)
+}
+
+func testMergeSlice_indexWise(t *testing.T) {
+	to := mergeCluster{Servers: []mergeServer{{1, "h1", 80}}, Ports: []int{1, 2, 3}}
+	from := mergeCluster{Servers: []mergeServer{{1, "h1", 81}, {2, "h2", 82}}, Ports: []int{9}}
+	if err := NewMerger(from, WithSliceStrategy(SliceIndexWise)).MergeTo(&to); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []mergeServer{{1, "h1", 81}, {2, "h2", 82}}, to.Servers)
+	assert.Equal(t, []int{9, 2, 3}, to.Ports)
+
+	arr := [2]int{1, 2}
+	if err := NewMerger([]int{5, 6, 7}).MergeTo(&arr); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, [2]int{5, 6}, arr)
+}
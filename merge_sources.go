@@ -0,0 +1,74 @@
+package ref
+
+// MergeAll merges the sources into to in order, so the latter sources
+// win, but the zero fields of the struct sources are unset and never
+// overwrite the former ones. It returns the provenance of the merged
+// values, see Merger.Provenance. For example:
+//
+//	var cfg Config
+//	prov, err := ref.MergeAll(&cfg, defaults, fileSettings, envSettings)
+//	fmt.Println(prov["Server.Port"]) // 2, it's set by envSettings
+//
+// Use NewMerger and Merger.Add for the options such as
+// WithConflictPolicy.
+func MergeAll(to interface{}, sources ...interface{}) (provenance map[string]int, err error) {
+	if len(sources) == 0 {
+		return make(map[string]int), nil
+	}
+	m := NewMerger(sources[0])
+	for _, src := range sources[1:] {
+		m.Add(src)
+	}
+	err = m.MergeTo(to)
+	return m.Provenance(), err
+}
+
+// Add appends a source which is merged after the sources of NewMerger
+// and the previous Add.
+//
+//	err := ref.NewMerger(defaults).Add(fileSettings).Add(flags).MergeTo(&cfg)
+func (m *Merger) Add(src interface{}) *Merger {
+	m.sources = append(m.sources, ValueOf(src))
+	return m
+}
+
+// Provenance returns the index of source (0 for the source of
+// NewMerger, 1 for the first Add, ...) which set the value at each path
+// in the last MergeTo. The paths are of the source values, such as
+// "Server.Port" or "servers[1].port". If several sources set a value,
+// the last one is recorded, and a source which has the same value as
+// the target doesn't set it.
+func (m *Merger) Provenance() map[string]int {
+	p := make(map[string]int, len(m.provenance))
+	for path, layer := range m.provenance {
+		p[path] = layer
+	}
+	return p
+}
+
+// SourceOf returns the index of source which set the value at path, see
+// Provenance. The path is matched case-insensitively, and the
+// underscores and hyphens are ignored, so "server.port" matches the
+// value set by a map source at "Server.Port".
+func (m *Merger) SourceOf(path string) (index int, ok bool) {
+	if index, ok = m.provenance[path]; !ok {
+		var raw string
+		if raw, ok = m.provKeys[normalizeKey(path)]; ok {
+			index = m.provenance[raw]
+		}
+	}
+	return
+}
+
+// record notes the current source sets the value at the current path
+func (m *Merger) record() {
+	if m.provenance == nil {
+		return
+	}
+	key := normalizeKey(m.path)
+	if raw, ok := m.provKeys[key]; ok && raw != m.path {
+		delete(m.provenance, raw)
+	}
+	m.provKeys[key] = m.path
+	m.provenance[m.path] = m.layer
+}
@@ -0,0 +1,109 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"testing"
+)
+
+type layeredServer struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type layeredApp struct {
+	Name   string        `json:"name"`
+	Server layeredServer `json:"server"`
+	Tags   []string      `json:"tags"`
+}
+
+func TestMergeSources(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("MergeAll", testMergeSources_mergeAll)
+	t.Run("MergeAll: struct layers", testMergeSources_structLayers)
+	t.Run("Add", testMergeSources_add)
+	t.Run("errors", testMergeSources_errors)
+}
+
+func testMergeSources_mergeAll(t *testing.T) {
+	defaults := layeredApp{Name: "app", Server: layeredServer{Host: "localhost", Port: 80}}
+	file := map[string]interface{}{"server": map[string]interface{}{"host": "example.com"}, "tags": []string{"a"}}
+	env := map[string]interface{}{"server": map[string]interface{}{"port": "8080"}}
+
+	var app layeredApp
+	prov, err := MergeAll(&app, defaults, file, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, layeredApp{Name: "app", Server: layeredServer{Host: "example.com", Port: 8080}, Tags: []string{"a"}}, app)
+	assert.Equal(t, map[string]int{
+		"Name":        0,
+		"server.host": 1,
+		"tags":        1,
+		"server.port": 2,
+	}, prov)
+
+	prov, err = MergeAll(&app)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(prov))
+}
+
+func testMergeSources_structLayers(t *testing.T) {
+	defaults := layeredApp{Name: "app", Server: layeredServer{Host: "a", Port: 8080}, Tags: []string{"x"}}
+	file := layeredApp{Server: layeredServer{Host: "b"}}
+	env := layeredApp{Name: "app2"}
+
+	// the zero fields of the latter layers are unset
+	var app layeredApp
+	prov, err := MergeAll(&app, defaults, file, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, layeredApp{Name: "app2", Server: layeredServer{Host: "b", Port: 8080}, Tags: []string{"x"}}, app)
+	assert.Equal(t, map[string]int{
+		"Name":        2,
+		"Server.Host": 1,
+		"Server.Port": 0,
+		"Tags":        0,
+	}, prov)
+}
+
+func testMergeSources_add(t *testing.T) {
+	var app layeredApp
+	m := NewMerger(layeredApp{Server: layeredServer{Port: 80}}, WithConflictPolicy(ConflictKeep)).
+		Add(map[string]interface{}{"server": map[string]interface{}{"port": 8080, "host": "h1"}}).
+		Add(layeredApp{Name: "app"})
+	if err := m.MergeTo(&app); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, layeredApp{Name: "app", Server: layeredServer{Host: "h1", Port: 80}}, app)
+
+	for _, c := range []struct {
+		path  string
+		index int
+	}{
+		{"Server.Port", 0},
+		{"server.port", 0},
+		{"Server.Host", 1},
+		{"name", 2},
+	} {
+		index, ok := m.SourceOf(c.path)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, c.index, index)
+	}
+	_, ok := m.SourceOf("Tags")
+	assert.Equal(t, false, ok)
+}
+
+func testMergeSources_errors(t *testing.T) {
+	var app layeredApp
+	m := NewMerger(map[string]interface{}{"server": map[string]interface{}{"port": "x"}}).
+		Add(map[string]interface{}{"name": "app"})
+	assert.Error(t, m.MergeTo(&app))
+	assert.Equal(t, "app", app.Name)
+
+	// the errors are cleared by the next MergeTo
+	m = NewMerger(map[string]interface{}{"name": "app2"})
+	assert.NoError(t, m.MergeTo(&app))
+	assert.Equal(t, "app2", app.Name)
+}
@@ -2,6 +2,7 @@ package ref
 
 import (
 	"github.com/hedzr/assert"
+	"gopkg.in/hedzr/errors.v2"
 	"testing"
 )
 
@@ -12,6 +13,11 @@ func TestMergeMap(t *testing.T) {
 	t.Run("map -> struct", testMergeMapStruct)
 	t.Run("basics", testMergeMapBasics)
 	t.Run("primitive types", testMergePrimitiveTypes)
+	t.Run("struct -> map", testMergeStructMap)
+	t.Run("struct, map -> slice", testMergeToSlice)
+	t.Run("slice -> struct, map", testMergeSliceObj)
+	t.Run("pointers", testMergePointers)
+	t.Run("incompatible shapes", testMergeIncompatible)
 }
 
 func testMergePrimitiveTypes(t *testing.T) {
@@ -46,13 +52,13 @@ func testMergePrimitiveTypes(t *testing.T) {
 		t.Fatalf("merge map error: %v", err)
 	}
 	t.Logf("vs = %v", vs)
-	assert.Equal(t, "Y", vs)
+	assert.Equal(t, "89", vs)
 
 	if err = NewMerger(65).MergeTo(&vs); err != nil {
 		t.Fatalf("merge map error: %v", err)
 	}
 	t.Logf("vs = %v", vs)
-	assert.Equal(t, "A", vs)
+	assert.Equal(t, "65", vs)
 
 }
 
@@ -158,3 +164,140 @@ func testMergeMapBasics(t *testing.T) {
 	assert.Equal(t, []string{"aa", "bb", "cc"}, m4["g"].(map[string]interface{})["e"])
 	assert.Equal(t, user1, m4["f"])
 }
+
+type mergeBase struct {
+	ID int `json:"id"`
+}
+
+type mergeItem struct {
+	mergeBase
+	Name  string            `json:"name"`
+	Tags  []string          `json:"tags,omitempty"`
+	Attrs map[string]string `json:"attrs"`
+	Inner *mergeServer      `json:"inner"`
+	Skip  string            `json:"-"`
+}
+
+func testMergeStructMap(t *testing.T) {
+	to := map[string]interface{}{"name": "old", "tags": []string{"a"}, "extra": 1}
+	from := mergeItem{
+		mergeBase: mergeBase{ID: 7},
+		Name:      "new",
+		Tags:      []string{"b"},
+		Inner:     &mergeServer{ID: 1, Host: "h1"},
+		Skip:      "skipped",
+	}
+	if err := NewMerger(from).MergeTo(&to); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]interface{}{
+		"id":    7,
+		"name":  "new",
+		"tags":  []string{"a", "b"},
+		"extra": 1,
+		"inner": mergeServer{ID: 1, Host: "h1"},
+	}, to)
+
+	// a struct is merged into the struct held by a map entry
+	nested := map[string]interface{}{"item": mergeItem{Name: "x", Tags: []string{"t"}}}
+	if err := NewMerger(map[string]interface{}{"item": mergeItem{Tags: []string{"u"}}}).MergeTo(&nested); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"t", "u"}, nested["item"].(mergeItem).Tags)
+
+	var sm map[string]string
+	if err := NewMerger(mergeServer{Host: "h2", Port: 80}).MergeTo(&sm); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"host": "h2", "port": "80"}, sm) // the zero ID is unset
+}
+
+func testMergeToSlice(t *testing.T) {
+	servers := []mergeServer{{1, "h1", 80}}
+	if err := NewMerger(mergeServer{2, "h2", 90}).MergeTo(&servers); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []mergeServer{{1, "h1", 80}, {2, "h2", 90}}, servers)
+
+	// by key, the matched element is updated
+	if err := NewMerger(map[string]interface{}{"id": 1, "port": 8080}, WithSliceStrategy(SliceByKey, "ID")).MergeTo(&servers); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []mergeServer{{1, "h1", 8080}, {2, "h2", 90}}, servers)
+
+	var list []interface{}
+	if err := NewMerger(map[string]interface{}{"a": 1}).MergeTo(&list); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []interface{}{map[string]interface{}{"a": 1}}, list)
+
+	// the struct field
+	c := mergeCluster{Servers: servers[:1]}
+	if err := NewMerger(map[string]interface{}{"Servers": map[string]interface{}{"id": 3, "host": "h3"}}).MergeTo(&c); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []mergeServer{{1, "h1", 8080}, {3, "h3", 0}}, c.Servers)
+}
+
+func testMergeSliceObj(t *testing.T) {
+	var s mergeServer
+	err := NewMerger([]interface{}{
+		map[string]interface{}{"id": 1, "host": "h1"},
+		mergeServer{Host: "h2", Port: 80},
+	}).MergeTo(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, mergeServer{ID: 1, Host: "h2", Port: 80}, s) // the zero ID is unset
+
+	m := map[string]interface{}{"a": 1}
+	if err = NewMerger([]map[string]interface{}{{"b": 2}, {"a": 3}}).MergeTo(&m); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]interface{}{"a": 3, "b": 2}, m)
+}
+
+func testMergePointers(t *testing.T) {
+	to := map[string]interface{}{"p": &mergeServer{ID: 1, Host: "h1", Port: 80}}
+	if err := NewMerger(map[string]interface{}{"p": &mergeServer{ID: 1, Host: "h2", Port: 80}}).MergeTo(&to); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &mergeServer{ID: 1, Host: "h2", Port: 80}, to["p"])
+
+	var item mergeItem
+	err := NewMerger(map[string]interface{}{
+		"inner": map[string]interface{}{"host": "h3"},
+		"attrs": map[string]interface{}{"k": "v"},
+	}).MergeTo(&item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &mergeServer{Host: "h3"}, item.Inner)
+	assert.Equal(t, map[string]string{"k": "v"}, item.Attrs)
+
+	var ps *mergeServer
+	if err = NewMerger(mergeServer{Port: 1}).MergeTo(&ps); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &mergeServer{Port: 1}, ps)
+}
+
+func testMergeIncompatible(t *testing.T) {
+	var uk *UnsupportedKindError
+
+	var i int
+	err := NewMerger(mergeServer{}).MergeTo(&i)
+	if !errors.As(err, &uk) {
+		t.Fatalf("expecting an UnsupportedKindError but got %v", err)
+	}
+
+	var f float64
+	assert.Error(t, NewMerger([]int{1}).MergeTo(&f))
+	assert.Error(t, NewMerger(map[string]interface{}{"a": 1}).MergeTo(&f))
+	assert.Error(t, NewMerger([]int{1, 2}).MergeTo(&mergeServer{}))
+
+	var im map[int]interface{}
+	if err = NewMerger(mergeServer{}).MergeTo(&im); !errors.As(err, &uk) {
+		t.Fatalf("expecting an UnsupportedKindError but got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package ref
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SnakeToPascal is a NameMappingRule which maps the snake_case names
+// to PascalCase, such as "user_name" -> "UserName".
+func SnakeToPascal(fromName string) (toName string, mapped bool) {
+	parts := strings.Split(fromName, "_")
+	for i, p := range parts {
+		parts[i] = upperFirst(p)
+	}
+	return ruleResult(fromName, strings.Join(parts, ""))
+}
+
+// PascalToSnake is a NameMappingRule which maps the PascalCase (and
+// camelCase) names to snake_case, such as "UserName" -> "user_name".
+// The acronyms are kept together: "DBHost" -> "db_host", "UserID" ->
+// "user_id".
+func PascalToSnake(fromName string) (toName string, mapped bool) {
+	runes := []rune(fromName)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' &&
+				(!unicode.IsUpper(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return ruleResult(fromName, sb.String())
+}
+
+// CamelToPascal is a NameMappingRule which maps the camelCase names to
+// PascalCase, such as "userName" -> "UserName".
+func CamelToPascal(fromName string) (toName string, mapped bool) {
+	return ruleResult(fromName, upperFirst(fromName))
+}
+
+// StripPrefix returns a NameMappingRule which removes the prefix from
+// the names, such as StripPrefix("Db"): "DbHost" -> "Host".
+func StripPrefix(prefix string) NameMappingRule {
+	return func(fromName string) (toName string, mapped bool) {
+		if prefix == "" || !strings.HasPrefix(fromName, prefix) || fromName == prefix {
+			return fromName, false
+		}
+		return fromName[len(prefix):], true
+	}
+}
+
+// AddSuffix returns a NameMappingRule which appends the suffix to the
+// names, such as AddSuffix("Dto"): "Address" -> "AddressDto".
+func AddSuffix(suffix string) NameMappingRule {
+	return func(fromName string) (toName string, mapped bool) {
+		return ruleResult(fromName, fromName+suffix)
+	}
+}
+
+// ChainRules returns a NameMappingRule which applies the rules one by
+// one, each of them takes the output of the previous one. The name is
+// mapped if any of the rules mapped it. For example:
+//
+//	ref.Clone(row, &user, ref.WithNameMappingsRule(
+//		ref.ChainRules(ref.StripPrefix("db_"), ref.SnakeToPascal)))
+//
+// maps "db_user_name" to "UserName".
+func ChainRules(rules ...NameMappingRule) NameMappingRule {
+	return func(fromName string) (toName string, mapped bool) {
+		toName = fromName
+		for _, rule := range rules {
+			if rule == nil {
+				continue
+			}
+			if n, ok := rule(toName); ok {
+				toName, mapped = n, true
+			}
+		}
+		return
+	}
+}
+
+func ruleResult(fromName, toName string) (string, bool) {
+	return toName, toName != fromName
+}
+
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if n == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
@@ -0,0 +1,81 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"testing"
+)
+
+func TestNameMappingRules(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("rules", testNameMappingRules_rules)
+	t.Run("clone struct with rules", testNameMappingRules_cloneStruct)
+	t.Run("clone map with rules", testNameMappingRules_cloneMap)
+}
+
+func testNameMappingRules_rules(t *testing.T) {
+	for _, tc := range []struct {
+		rule   NameMappingRule
+		from   string
+		to     string
+		mapped bool
+	}{
+		{SnakeToPascal, "user_name", "UserName", true},
+		{SnakeToPascal, "id", "Id", true},
+		{SnakeToPascal, "Name", "Name", false},
+		{PascalToSnake, "UserName", "user_name", true},
+		{PascalToSnake, "DBHost", "db_host", true},
+		{PascalToSnake, "UserID", "user_id", true},
+		{PascalToSnake, "userName", "user_name", true},
+		{PascalToSnake, "name", "name", false},
+		{CamelToPascal, "userName", "UserName", true},
+		{CamelToPascal, "UserName", "UserName", false},
+		{StripPrefix("Db"), "DbHost", "Host", true},
+		{StripPrefix("Db"), "Db", "Db", false},
+		{StripPrefix("Db"), "Host", "Host", false},
+		{AddSuffix("Dto"), "Address", "AddressDto", true},
+		{ChainRules(StripPrefix("db_"), SnakeToPascal), "db_user_name", "UserName", true},
+		{ChainRules(PascalToSnake, nil), "Name", "name", true},
+		{ChainRules(StripPrefix("x")), "Name", "Name", false},
+	} {
+		to, mapped := tc.rule(tc.from)
+		assert.Equal(t, tc.to, to)
+		assert.Equal(t, tc.mapped, mapped)
+	}
+}
+
+type nrRow struct {
+	DbUserName string
+	DbAge      int
+}
+
+type nrUser struct {
+	UserName string
+	Age      int
+}
+
+type nrUserDto struct {
+	UserNameDto string
+	AgeDto      int
+}
+
+func testNameMappingRules_cloneStruct(t *testing.T) {
+	var u nrUser
+	Clone(nrRow{DbUserName: "tom", DbAge: 18}, &u, WithNameMappingsRule(StripPrefix("Db")))
+	assert.Equal(t, nrUser{UserName: "tom", Age: 18}, u)
+
+	var dto nrUserDto
+	Clone(nrRow{DbUserName: "tom", DbAge: 18}, &dto, WithNameMappingsRule(ChainRules(StripPrefix("Db"), AddSuffix("Dto"))))
+	assert.Equal(t, nrUserDto{UserNameDto: "tom", AgeDto: 18}, dto)
+}
+
+func testNameMappingRules_cloneMap(t *testing.T) {
+	var u nrUser
+	m := map[string]interface{}{"db_user_name": "tom", "db_age": 18}
+	Clone(m, &u, WithNameMappingsRule(ChainRules(StripPrefix("db_"), SnakeToPascal)))
+	assert.Equal(t, nrUser{UserName: "tom", Age: 18}, u)
+
+	var dto nrUserDto
+	Clone(map[string]interface{}{"user_name": "tom"}, &dto, WithNameMappingsRule(ChainRules(SnakeToPascal, AddSuffix("Dto"))))
+	assert.Equal(t, "tom", dto.UserNameDto)
+}
@@ -0,0 +1,59 @@
+package ref
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pathPattern is a compiled dotted path pattern, such as:
+//
+//	Profile.Password
+//	Orders[*].Internal
+//	Orders[2].*.ID
+//
+// A '[*]' matches any index of slice/array or any key of map, and a
+// '*' matches any name of a field.
+type pathPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func compilePathPattern(pattern string) pathPattern {
+	var sb strings.Builder
+	sb.WriteByte('^')
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "[*]"):
+			sb.WriteString(`\[[^\]]*\]`)
+			i += 2
+		case pattern[i] == '*':
+			sb.WriteString(`[^.\[\]]*`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	sb.WriteByte('$')
+	return pathPattern{pattern: pattern, re: regexp.MustCompile(sb.String())}
+}
+
+// Match reports whether the path matches with this pattern
+func (p pathPattern) Match(path string) bool {
+	if p.pattern == path {
+		return true
+	}
+	return p.re.MatchString(path)
+}
+
+// joinPath appends a field name to the parent path
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// indexPath appends an index of slice, or a key of map, to the parent path
+func indexPath(parent string, index interface{}) string {
+	return fmt.Sprintf("%s[%v]", parent, index)
+}
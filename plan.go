@@ -0,0 +1,195 @@
+package ref
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// clonePlan is the compiled execution plan for copying a struct type
+// to another type. The plans are cached per (source type, target type,
+// tag name), so the cloner doesn't need to re-walk the fields, parse
+// the struct tags and look up the target fields by names for each
+// copying.
+//
+// The call-site options (such as WithNameMappings) are still applied
+// at copying time, a field which is mapped to another name will be
+// resolved dynamically.
+type clonePlan struct {
+	fields  []planField
+	methods []planMethod
+}
+
+type planKey struct {
+	from, to reflect.Type
+	tagName  string
+}
+
+// planField is a source field and its default target
+type planField struct {
+	index    int
+	field    reflect.StructField
+	exported bool
+	rules    copyTag // the rules of source field
+	target   planTarget
+}
+
+// planMethod is a source method (getter) and its default target field
+type planMethod struct {
+	method  reflect.Method // in the method set of *T
+	withErr bool           // the getter returns (T, error)
+	target  planTarget
+}
+
+// planTarget is the resolved target of a source field or method
+type planTarget struct {
+	name        string // the final name of target field or method
+	rules       copyTag
+	field       reflect.StructField
+	isField     bool
+	methodIndex int // the index of method (setter) in the method set of *T, or -1
+}
+
+func (t planTarget) found() bool { return t.isField || t.methodIndex >= 0 }
+
+var clonePlans sync.Map // map[planKey]*clonePlan
+
+// planOf returns the cached plan for copying fromType to toType
+func (c cloner) planOf(fromType, toType reflect.Type) (plan *clonePlan) {
+	key := planKey{fromType, toType, c.tagName}
+	if p, ok := clonePlans.Load(key); ok {
+		return p.(*clonePlan)
+	}
+	p, _ := clonePlans.LoadOrStore(key, c.buildPlan(fromType, toType))
+	return p.(*clonePlan)
+}
+
+func (c cloner) buildPlan(fromType, toType reflect.Type) (plan *clonePlan) {
+	plan = &clonePlan{}
+	toStruct := toType.Kind() == reflect.Struct
+
+	for i := 0; i < fromType.NumField(); i++ {
+		field := fromType.Field(i)
+		pf := planField{
+			index:    i,
+			field:    field,
+			exported: isExportableField(field),
+			rules:    c.fieldTag(field),
+			target:   planTarget{name: field.Name, methodIndex: -1},
+		}
+		if pf.exported && !field.Anonymous {
+			name := field.Name
+			if pf.rules.name != "" {
+				name = pf.rules.name
+			}
+			pf.target = planTarget{name: name, rules: pf.rules, methodIndex: -1}
+			if toStruct {
+				pf.target = c.resolveTarget(toType, name, pf.rules)
+			}
+		}
+		plan.fields = append(plan.fields, pf)
+	}
+
+	if !toStruct {
+		return
+	}
+	ptrType := reflect.PtrTo(fromType)
+	for i := 0; i < ptrType.NumMethod(); i++ {
+		method := ptrType.Method(i)
+		withErr, ok := isGetter(method.Type)
+		if !ok || !isExportableMethod(method) {
+			continue
+		}
+		pm := planMethod{method: method, withErr: withErr, target: planTarget{name: method.Name, methodIndex: -1}}
+		ttf, found := toType.FieldByName(method.Name)
+		if name := strings.TrimPrefix(method.Name, "Get"); !found && name != method.Name && name != "" {
+			// GetX() -> X, unless the source has the field X too
+			if _, dup := fromType.FieldByName(name); !dup {
+				pm.target.name = name
+				ttf, found = toType.FieldByName(name)
+			}
+		}
+		if found {
+			pm.target.field, pm.target.isField = ttf, true
+			pm.target.rules = c.fieldTag(ttf)
+		}
+		plan.methods = append(plan.methods, pm)
+	}
+	return
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// isGetter tests whether a method type (with the receiver) is a getter:
+// func (T) X() V, or func (T) X() (V, error).
+func isGetter(mt reflect.Type) (withErr, ok bool) {
+	if mt.NumIn() != 1 {
+		return
+	}
+	switch mt.NumOut() {
+	case 1:
+		return false, true
+	case 2:
+		return true, mt.Out(1) == errorType
+	}
+	return
+}
+
+// isSetter tests whether a method type (with the receiver) is a setter:
+// func (*T) X(v V), or func (*T) X(v V) error.
+func isSetter(mt reflect.Type) bool {
+	return mt.NumIn() == 2 && (mt.NumOut() == 0 || mt.NumOut() == 1 && mt.Out(0) == errorType)
+}
+
+// resolveTarget finds the target field, or the target method (setter)
+// X(v) or SetX(v) if no such field, in the struct type toType.
+func (c cloner) resolveTarget(toType reflect.Type, toName string, rules copyTag) (t planTarget) {
+	t = planTarget{name: toName, rules: rules, methodIndex: -1}
+	if ttf, ok := c.lookupTargetField(toType, toName); ok {
+		t.name, t.field, t.isField = ttf.Name, ttf, true
+		t.rules = rules.merge(c.fieldTag(ttf))
+		return
+	}
+	ptrType := reflect.PtrTo(toType)
+	for _, name := range []string{toName, "Set" + toName} {
+		if m, ok := ptrType.MethodByName(name); ok && isSetter(m.Type) {
+			t.name, t.methodIndex = name, m.Index
+			return
+		}
+	}
+	return
+}
+
+// structInfo caches the fields of a struct type which are renamed by
+// the struct tag, see also cloner.lookupTargetField.
+type structInfo struct {
+	byTagName map[string]reflect.StructField
+}
+
+type structInfoKey struct {
+	typ     reflect.Type
+	tagName string
+}
+
+var structInfos sync.Map // map[structInfoKey]*structInfo
+
+func (c cloner) structInfoOf(typ reflect.Type) *structInfo {
+	key := structInfoKey{typ, c.tagName}
+	if si, ok := structInfos.Load(key); ok {
+		return si.(*structInfo)
+	}
+	si := &structInfo{byTagName: make(map[string]reflect.StructField)}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if !isExportableField(f) {
+			continue
+		}
+		if name := c.fieldTag(f).name; name != "" {
+			if _, ok := si.byTagName[name]; !ok {
+				si.byTagName[name] = f
+			}
+		}
+	}
+	actual, _ := structInfos.LoadOrStore(key, si)
+	return actual.(*structInfo)
+}
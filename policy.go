@@ -0,0 +1,78 @@
+package ref
+
+import "reflect"
+
+// CopyPolicy specifies whether the values of the reference kinds are
+// cloned deeply or shared between the source and the target, see
+// WithCopyPolicy.
+type CopyPolicy uint
+
+const (
+	// DeepPointers clones the pointees, or else the pointers are shared
+	DeepPointers CopyPolicy = 1 << iota
+	// DeepMaps clones the maps, or else the maps are shared
+	DeepMaps
+	// DeepSlices clones the slices, or else the slices are shared
+	DeepSlices
+	// ShareFuncs assigns the funcs to the target, or else they're not copied
+	ShareFuncs
+	// ShareChans assigns the chans to the target, or else they're not copied
+	ShareChans
+
+	// DeepCopyPolicy is the default policy, the mutations of a clone
+	// never leak back into the original.
+	DeepCopyPolicy = DeepPointers | DeepMaps | DeepSlices | ShareFuncs | ShareChans
+	// ShallowCopyPolicy shares all of the pointers, maps and slices
+	// if the source and target have the same type.
+	ShallowCopyPolicy = ShareFuncs | ShareChans
+)
+
+// WithCopyPolicy specifies how the pointers, maps, slices, funcs and
+// chans are copied, the default is DeepCopyPolicy. For example,
+//
+//	ref.Clone(from, &to, ref.WithCopyPolicy(ref.DeepPointers|ref.ShareFuncs))
+//
+// clones the pointees, but shares the maps and slices with the source.
+//
+// A policy takes effect only if a source value can be assigned to the
+// target, the values of the different types are always converted into
+// the newly allocated ones.
+func WithCopyPolicy(policy CopyPolicy) CloneOpt {
+	return func(c *cloner) {
+		c.policy = policy
+	}
+}
+
+// sharing reports how a source value of type ft is copied to tt by the
+// copy policy: share means assigning the source value directly, skip
+// means keeping the target as is.
+func (c cloner) sharing(ft, tt reflect.Type) (share, skip bool) {
+	if !ft.AssignableTo(tt) {
+		return
+	}
+	switch ft.Kind() {
+	case reflect.Ptr:
+		share = c.policy&DeepPointers == 0
+	case reflect.Map:
+		share = c.policy&DeepMaps == 0
+	case reflect.Slice:
+		share = c.policy&DeepSlices == 0
+	case reflect.Func:
+		share = c.policy&ShareFuncs != 0
+		skip = !share
+	case reflect.Chan:
+		share = c.policy&ShareChans != 0
+		skip = !share
+	}
+	return
+}
+
+// holdsReferences tests whether the values of kind k might refer to
+// the memory which would be shared by an assignment.
+func holdsReferences(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Array, reflect.Interface, reflect.Struct:
+		return true
+	}
+	return false
+}
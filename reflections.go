@@ -411,6 +411,16 @@ func isExportableField(field reflect.StructField) bool {
 	return field.PkgPath == ""
 }
 
+// hasExportedFields reports whether a struct type has any exported field
+func hasExportedFields(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		if isExportableField(typ.Field(i)) {
+			return true
+		}
+	}
+	return false
+}
+
 func isExportableMethod(mtd reflect.Method) bool {
 	// PkgPath is empty for exported fields.
 	return mtd.PkgPath == ""
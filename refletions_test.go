@@ -343,7 +343,7 @@ func testSetFieldInvalidValueType(t *testing.T) {
 		Dummy: "test",
 	}
 
-	err := SetField(&dummyStruct, "Yummy", "123")
+	err := SetField(&dummyStruct, "Yummy", "abc")
 	assert.Error(t, err)
 }
 
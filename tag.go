@@ -0,0 +1,88 @@
+package ref
+
+import (
+	"reflect"
+	"strings"
+)
+
+// DefaultCopyTagName is the default struct tag name which the cloner
+// reads the per-field cloning rules from, see also WithCopyTagName.
+//
+// The rules are comma-separated:
+//
+//	type User struct {
+//		ID       int64  `copy:"-"`                   // never copied
+//		FullName string `copy:"name=Name,omitempty"` // copied to/from Name, keep target if source is zero
+//		Tags     []string `copy:"deep"`              // always cloned deeply
+//		Owner    *User  `copy:"shallow"`             // always shared with the source
+//	}
+//
+// A bare first element is treated as the name too, so `copy:"Name"`
+// equals to `copy:"name=Name"`.
+const DefaultCopyTagName = "copy"
+
+// copyTag holds the cloning rules parsed from a struct field tag
+type copyTag struct {
+	skip      bool
+	name      string
+	omitEmpty bool
+	deep      bool
+	shallow   bool
+}
+
+func parseCopyTag(tag string) (ct copyTag) {
+	if tag == "" {
+		return
+	}
+	if tag == "-" {
+		ct.skip = true
+		return
+	}
+	for i, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+		case part == "omitempty":
+			ct.omitEmpty = true
+		case part == "deep":
+			ct.deep, ct.shallow = true, false
+		case part == "shallow":
+			ct.deep, ct.shallow = false, true
+		case strings.HasPrefix(part, "name="):
+			ct.name = strings.TrimSpace(part[len("name="):])
+		case i == 0 && !strings.Contains(part, "="):
+			ct.name = part
+		}
+	}
+	return
+}
+
+// merge combines the rules of source field and target field, the
+// source side wins if both of them specify deep or shallow.
+func (ct copyTag) merge(target copyTag) copyTag {
+	ct.skip = ct.skip || target.skip
+	ct.omitEmpty = ct.omitEmpty || target.omitEmpty
+	if !ct.deep && !ct.shallow {
+		ct.deep, ct.shallow = target.deep, target.shallow
+	}
+	return ct
+}
+
+// fieldTag returns the cloning rules of a struct field
+func (c cloner) fieldTag(field reflect.StructField) (ct copyTag) {
+	if c.tagName != "" {
+		ct = parseCopyTag(field.Tag.Get(c.tagName))
+	}
+	return
+}
+
+// lookupTargetField finds the target field by name, a target field
+// which declares `name=<toName>` in its tag is preferred.
+func (c cloner) lookupTargetField(toType reflect.Type, toName string) (sf reflect.StructField, ok bool) {
+	if c.tagName != "" {
+		if sf, ok = c.structInfoOf(toType).byTagName[toName]; ok {
+			return
+		}
+	}
+	return toType.FieldByName(toName)
+}
@@ -0,0 +1,262 @@
+package ref
+
+import (
+	"reflect"
+	"strings"
+)
+
+type (
+	// ToMapOpt is functional option functor for ToMap()
+	ToMapOpt func(m *mapper)
+
+	// mapper converts a struct to a map[string]interface{} tree
+	mapper struct {
+		tagNames  []string
+		omitEmpty bool
+		separator string // flatten the nested maps with it if not empty
+
+		visiting map[uintptr]bool // the pointers in converting, to break the cycles
+	}
+)
+
+// ToMap converts a struct (or a pointer to struct, a map) to a
+// map[string]interface{} tree, for example:
+//
+//	type User struct {
+//		Name    string   `json:"name"`
+//		Email   string   `json:"email,omitempty"`
+//		Address *Address `json:"address"`
+//	}
+//	m := ref.ToMap(user)
+//	// map[address:map[city:... street:...] name:...]
+//
+// The key names are taken from the struct tags (see WithMapKeyTagNames),
+// or the field names if no tags. A field with tag "-" is ignored, and
+// a field with tag option "omitempty" is ignored if it's zero.
+//
+// The nested structs, pointers to struct and maps are converted to the
+// nested maps recursively, and the elements of slices and arrays are
+// converted too. The fields of an embedded struct are promoted into
+// its owner if it has no tag name. The structs which have no exported
+// fields (such as time.Time) are kept as is.
+//
+// The nested maps can be flattened into the dotted keys by
+// WithMapFlatten:
+//
+//	m := ref.ToMap(user, ref.WithMapFlatten("."))
+//	// map[address.city:... address.street:... name:...]
+//
+// ToMap returns nil if obj is not a struct or map.
+func ToMap(obj interface{}, opts ...ToMapOpt) map[string]interface{} {
+	m := &mapper{
+		tagNames: DefaultKeyTagNames,
+		visiting: make(map[uintptr]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	v := m.convert(reflect.ValueOf(obj))
+	out, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if m.separator != "" {
+		flat := make(map[string]interface{})
+		m.flatten(flat, "", out)
+		out = flat
+	}
+	return out
+}
+
+// WithMapKeyTagNames specifies the struct tag names for the key names
+// of ToMap, the default is DefaultKeyTagNames. The first tag which has
+// a name is used.
+func WithMapKeyTagNames(tagNames ...string) ToMapOpt {
+	return func(m *mapper) {
+		m.tagNames = tagNames
+	}
+}
+
+// WithMapOmitEmpty specifies whether all of the zero fields should be
+// ignored by ToMap, even if they have not the tag option "omitempty".
+func WithMapOmitEmpty(b bool) ToMapOpt {
+	return func(m *mapper) {
+		m.omitEmpty = b
+	}
+}
+
+// WithMapFlatten flattens the nested maps of ToMap into the top level
+// map, the keys are joined by separator, such as "a.b.c".
+func WithMapFlatten(separator string) ToMapOpt {
+	return func(m *mapper) {
+		m.separator = separator
+	}
+}
+
+// convert returns the map tree form of v
+func (m *mapper) convert(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return m.convert(v.Elem())
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		ptr := v.Pointer()
+		if m.visiting[ptr] {
+			return nil // a cycle
+		}
+		m.visiting[ptr] = true
+		defer delete(m.visiting, ptr)
+		return m.convert(v.Elem())
+
+	case reflect.Struct:
+		if !hasExportedFields(v.Type()) {
+			return v.Interface()
+		}
+		out := make(map[string]interface{})
+		m.structInto(out, v)
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			out[mapKeyString(k)] = m.convert(v.MapIndex(k))
+		}
+		return out
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if !needConvertToMap(v.Type().Elem()) {
+			return v.Interface()
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = m.convert(v.Index(i))
+		}
+		return out
+	}
+
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// structInto puts the exported fields of a struct into out
+func (m *mapper) structInto(out map[string]interface{}, v reflect.Value) {
+	typ := v.Type()
+	var embedded []reflect.Value
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		name, omitEmpty, skip := m.keyName(sf)
+		if skip {
+			continue
+		}
+
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" {
+			// promote the fields of the embedded struct
+			ev := fv
+			if ev.Kind() == reflect.Ptr {
+				if ev.IsNil() {
+					continue
+				}
+				ev = ev.Elem()
+			}
+			if ev.Kind() == reflect.Struct {
+				embedded = append(embedded, ev)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		if (omitEmpty || m.omitEmpty) && IsZero(fv) {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		out[name] = m.convert(fv)
+	}
+
+	for _, ev := range embedded {
+		promoted := make(map[string]interface{})
+		m.structInto(promoted, ev)
+		for k, x := range promoted {
+			if _, ok := out[k]; !ok { // not hidden by a shallower field
+				out[k] = x
+			}
+		}
+	}
+}
+
+// keyName returns the name in the struct tags, it's empty if the
+// field has no tag name.
+func (m *mapper) keyName(sf reflect.StructField) (name string, omitEmpty, skip bool) {
+	for _, tn := range m.tagNames {
+		tag, ok := sf.Tag.Lookup(tn)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if parts[0] == "-" && len(parts) == 1 {
+			return "", false, true
+		}
+		for _, opt := range parts[1:] {
+			if strings.TrimSpace(opt) == "omitempty" {
+				omitEmpty = true
+			}
+		}
+		if name = strings.TrimSpace(parts[0]); name != "" {
+			return
+		}
+	}
+	return
+}
+
+// flatten puts the entries of nested maps into out with the joined keys
+func (m *mapper) flatten(out map[string]interface{}, prefix string, in map[string]interface{}) {
+	for k, v := range in {
+		if prefix != "" {
+			k = prefix + m.separator + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			m.flatten(out, k, nested)
+			continue
+		}
+		out[k] = v
+	}
+}
+
+// needConvertToMap tests whether the values of typ might be converted
+// to the map trees.
+func needConvertToMap(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Interface, reflect.Map:
+		return true
+	case reflect.Struct:
+		return hasExportedFields(typ)
+	case reflect.Slice, reflect.Array:
+		return needConvertToMap(typ.Elem())
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package ref
+
+import (
+	"github.com/hedzr/assert"
+	"testing"
+	"time"
+)
+
+type tmAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street,omitempty"`
+}
+
+type tmAudit struct {
+	CreatedBy string `json:"created_by"`
+	Name      string `json:"name"` // hidden by tmUser.Name
+}
+
+type tmUser struct {
+	tmAudit
+	Name     string            `json:"name"`
+	Email    string            `json:"email,omitempty"`
+	Password string            `json:"-"`
+	Age      int               `yaml:"age"`
+	Born     time.Time         `json:"born"`
+	Address  *tmAddress        `json:"address"`
+	Others   []tmAddress       `json:"others"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Parent   *tmUser           `json:"parent,omitempty"`
+	secret   string
+}
+
+func newTmUser() *tmUser {
+	u := &tmUser{
+		tmAudit:  tmAudit{CreatedBy: "admin", Name: "x"},
+		Name:     "tom",
+		Password: "p",
+		Age:      18,
+		Born:     time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Address:  &tmAddress{City: "Paris"},
+		Others:   []tmAddress{{City: "Lyon", Street: "Rue"}},
+		Tags:     []string{"a"},
+		Labels:   map[string]string{"k": "v"},
+		secret:   "s",
+	}
+	u.Parent = u // a cycle
+	return u
+}
+
+func TestToMap(t *testing.T) {
+	defer initLogger(t)()
+
+	t.Run("ToMap: nested", testToMap_nested)
+	t.Run("ToMap: options", testToMap_options)
+	t.Run("ToMap: flatten", testToMap_flatten)
+	t.Run("Clone: struct -> map", testToMap_cloneStructToMap)
+}
+
+func testToMap_nested(t *testing.T) {
+	m := ToMap(newTmUser())
+	t.Logf("%v", m)
+
+	assert.Equal(t, map[string]interface{}{
+		"created_by": "admin",
+		"name":       "tom",
+		"age":        18,
+		"born":       time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		"address":    map[string]interface{}{"city": "Paris"},
+		"others":     []interface{}{map[string]interface{}{"city": "Lyon", "street": "Rue"}},
+		"tags":       []string{"a"},
+		"labels":     map[string]interface{}{"k": "v"},
+		"parent":     nil, // the cycle is broken
+	}, m)
+
+	assert.Nil(t, ToMap(1))
+}
+
+func testToMap_options(t *testing.T) {
+	u := newTmUser()
+	u.Parent = nil
+	m := ToMap(u, WithMapKeyTagNames("yaml"))
+	assert.Equal(t, "tom", m["Name"])
+	assert.Equal(t, 18, m["age"])
+	assert.Equal(t, "p", m["Password"])
+	assert.Equal(t, "", m["Email"])
+
+	m = ToMap(tmUser{Name: "tom"}, WithMapOmitEmpty(true))
+	assert.Equal(t, map[string]interface{}{"name": "tom"}, m)
+}
+
+func testToMap_flatten(t *testing.T) {
+	u := newTmUser()
+	m := ToMap(u, WithMapFlatten("."))
+	assert.Equal(t, "Paris", m["address.city"])
+	assert.Equal(t, "v", m["labels.k"])
+	assert.Equal(t, "tom", m["name"])
+	if _, ok := m["address"]; ok {
+		t.Fatal("the nested map should be flattened")
+	}
+}
+
+func testToMap_cloneStructToMap(t *testing.T) {
+	type namedKey string
+
+	var m map[string]interface{}
+	if err := CloneE(tmAddress{City: "Paris"}, &m); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, map[string]interface{}{"City": "Paris", "Street": ""}, m)
+
+	var ms map[namedKey]string
+	if err := CloneE(tmAddress{City: "Paris", Street: "Rue"}, &ms); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assert.Equal(t, map[namedKey]string{"City": "Paris", "Street": "Rue"}, ms)
+}
@@ -77,6 +77,16 @@ func isKindComplex(k reflect.Kind) bool {
 	return false
 }
 
+func isNumericKind(k reflect.Kind) bool {
+	return isKindInteger(k) || isKindFloat(k)
+}
+
+// isMergeableKind reports whether a value of kind k can be merged
+// into an existing value rather than replacing it
+func isMergeableKind(k reflect.Kind) bool {
+	return k == reflect.Struct || k == reflect.Map
+}
+
 func isStruct(obj interface{}) bool  { return reflect.TypeOf(obj).Kind() == reflect.Struct }
 func isPointer(obj interface{}) bool { return reflect.TypeOf(obj).Kind() == reflect.Ptr }
 
@@ -261,6 +271,7 @@ func (v Value) GetValue() (val interface{}) {
 }
 
 // TryConvert calls reflect.Convert safely, without panic threw.
+// See also the package-level TryConvert.
 func (v Value) TryConvert(t reflect.Type) (out reflect.Value, err error) {
 	return TryConvert(v.Value, t)
 }